@@ -0,0 +1,130 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/files"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/coverdir"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/tools/cover"
+)
+
+const (
+	profileFileUsage = "path to coverage profile file, may be repeated or a glob to merge several profiles"
+	coverDirUsage    = "path to a GOCOVERDIR written by a binary built with -cover, may be repeated"
+	staleUsage       = "what to do when the source changed after the profile was generated: fail, warn or ignore"
+)
+
+const (
+	staleFail   = "fail"
+	staleWarn   = "warn"
+	staleIgnore = "ignore"
+)
+
+var (
+	coverDirs    []string
+	staleProfile string
+)
+
+// loadProfiles parses and merges the profile files and the coverage data of each cover dir
+func loadProfiles(profilePaths []string) ([]*cover.Profile, error) {
+	sets := make([][]*cover.Profile, 0, len(coverDirs)+1)
+
+	if len(profilePaths) > 0 {
+		profiles, err := profile.ParseProfiles(profilePaths)
+		if err != nil {
+			return nil, err
+		}
+
+		sets = append(sets, profiles)
+	}
+
+	for _, dir := range coverDirs {
+		profiles, err := coverdir.ReadProfiles(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		sets = append(sets, profiles)
+	}
+
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("a profile file or cover dir is required")
+	}
+
+	return profile.MergeProfiles(sets...)
+}
+
+// mapPackagesForPath collects the project files for the src path in args and maps them to the merged profiles
+func mapPackagesForPath(args []string, profilePaths []string) (map[string][]profile.FunctionCoverage, error) {
+	profiles, err := loadProfiles(profilePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	srcPath := files.SetSrcPath(args)
+	ignoreDirs := strings.Split(skipDirs, ",")
+
+	projectFiles, err := files.FilesForPath(srcPath, ignoreDirs)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve files for path %v %v", srcPath, err)
+	}
+
+	fset := token.NewFileSet()
+
+	packageToFunctions, err := analyzer.MapProfilesToFunctions(profiles, projectFiles, fset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkStaleProfile(packageToFunctions); err != nil {
+		return nil, err
+	}
+
+	return packageToFunctions, nil
+}
+
+// checkStaleProfile lists the files which changed after the profile was generated, since their coverage would be
+// attributed to the wrong statements. It returns an error for stale files unless --stale-profile is warn or ignore.
+func checkStaleProfile(packageToFunctions map[string][]profile.FunctionCoverage) error {
+	switch staleProfile {
+	case staleIgnore:
+		return nil
+	case staleFail, staleWarn:
+	default:
+		return fmt.Errorf("unknown value %v for --stale-profile, expected one of fail, warn or ignore", staleProfile)
+	}
+
+	stale := analyzer.StaleFiles(packageToFunctions)
+	if len(stale) == 0 {
+		return nil
+	}
+
+	for _, f := range stale {
+		log.Warnf("profile is stale for %v: %v blocks do not line up with its statements", f.Path, len(f.Blocks))
+	}
+
+	if staleProfile == staleWarn {
+		return nil
+	}
+
+	return fmt.Errorf("profile is stale for %v files, regenerate it or set --stale-profile to warn", len(stale))
+}
@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -29,24 +30,53 @@ import (
 
 	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
 	"github.com/cvgw/gocheckcov/pkg/coverage/config"
+	"github.com/cvgw/gocheckcov/pkg/coverage/diff"
 	"github.com/cvgw/gocheckcov/pkg/coverage/files"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
 	"github.com/cvgw/gocheckcov/pkg/coverage/reporter"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 )
 
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatJUnit = "junit"
+)
+
+var reportWriters = map[string]func(io.Writer, reporter.Report) error{
+	formatJSON:  reporter.WriteJSON,
+	formatJUnit: reporter.WriteJUnit,
+}
+
 var (
 	noConfig       bool
 	configFile     string
-	ProfileFile    string
+	ProfileFiles   []string
 	printFunctions bool
+	printClosures  bool
 	printSrc       bool
 	minCov         float64
 	skipDirs       string
+	outputFormat   string
+	outputFile     string
+	diffBase       string
+	diffMinCov     float64
+	explain        bool
+	coverPkgAll    bool
+	maxCRAP        float64
+	printTree      bool
+	totalMinCov    float64
 	checkCmd       = &cobra.Command{
-		Use:   "check",
+		Use:   "check [path] [-- go test flags]",
 		Short: "Check whether pkg coverage meets specified minimum",
 		Run: func(cmd *cobra.Command, args []string) {
-			err := runCheckCommand(args)
+			var testFlags []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				args, testFlags = args[:dash], args[dash:]
+			}
+
+			err := runCheckCommand(args, testFlags)
 			if err != nil {
 				os.Exit(1)
 			}
@@ -54,11 +84,16 @@ var (
 	}
 )
 
-func runCheckCommand(args []string) error {
+func runCheckCommand(args, testFlags []string) error {
 	if verbose {
 		log.SetLevel(log.DebugLevel)
 	}
 
+	if err := validateOutputFlags(); err != nil {
+		log.Print(err)
+		return err
+	}
+
 	ignoreDirs := strings.Split(skipDirs, ",")
 	srcPath := files.SetSrcPath(args)
 	dir := srcPath
@@ -69,14 +104,21 @@ func runCheckCommand(args []string) error {
 		return err
 	}
 
-	profilePath := ProfileFile
-	if profilePath == "" {
-		pf, e := runTestsAndGenerateProfile(srcPath)
+	profilePaths := ProfileFiles
+	if len(profilePaths) > 0 || len(coverDirs) > 0 {
+		if len(testFlags) > 0 {
+			log.Warnf("ignoring test flags %v since tests are not run when a profile is given", testFlags)
+		}
+	} else {
+		pf, e := runTestsAndGenerateProfile(srcPath, projectFiles, testFlags)
 		if e != nil {
-			return fmt.Errorf("could not run tests %v", e)
+			e = fmt.Errorf("could not run tests %v", e)
+			log.Print(e)
+
+			return e
 		}
 
-		profilePath = pf.Name()
+		profilePaths = []string{pf.Name()}
 
 		defer func() {
 			if e := os.Remove(pf.Name()); e != nil {
@@ -85,19 +127,38 @@ func runCheckCommand(args []string) error {
 		}()
 	}
 
+	profiles, err := loadProfiles(profilePaths)
+	if err != nil {
+		log.Print(err)
+		return err
+	}
+
 	fset := token.NewFileSet()
 
-	packageToFunctions, err := analyzer.MapPackagesToFunctions(profilePath, projectFiles, fset)
+	packageToFunctions, err := analyzer.MapProfilesToFunctions(profiles, projectFiles, fset)
 	if err != nil {
 		log.Print(err)
 		return err
 	}
 
+	if err := checkStaleProfile(packageToFunctions); err != nil {
+		log.Print(err)
+		return err
+	}
+
 	cfContent, err := getConfig()
 	if err != nil {
 		return err
 	}
 
+	if diffBase != "" {
+		return checkDiffCoverage(packageToFunctions, dir, cfContent)
+	}
+
+	if outputFormat != formatText {
+		return writeReport(packageToFunctions, cfContent)
+	}
+
 	cliL := reporter.NewCliTabLogger()
 	defer cliL.Close()
 
@@ -106,10 +167,19 @@ func runCheckCommand(args []string) error {
 	}
 
 	v := reporter.Verifier{
-		Out:            cliL,
-		PrintFunctions: printFunctions,
-		PrintSrc:       printSrc,
-		MinCov:         minCov,
+		Out:             cliL,
+		PrintFunctions:  printFunctions,
+		IncludeClosures: printClosures,
+		PrintSrc:        printSrc,
+		MinCov:          minCov,
+		Explain:         explain,
+		MaxCRAP:         maxCRAP,
+		PrintTree:       printTree,
+		TotalMinCov:     totalMinCov,
+	}
+
+	if verbose {
+		v.PrintIgnoredRegions(packageToFunctions)
 	}
 
 	if _, err := v.ReportCoverage(packageToFunctions, printFunctions, cfContent); err != nil {
@@ -120,11 +190,150 @@ func runCheckCommand(args []string) error {
 	return nil
 }
 
+func checkDiffCoverage(
+	packageToFunctions map[string][]profile.FunctionCoverage,
+	srcPath string,
+	cfContent []byte,
+) error {
+	packageToFunctions, err := reporter.ExcludeFunctions(packageToFunctions, cfContent)
+	if err != nil {
+		log.Print(err)
+		return err
+	}
+
+	dir := srcPath
+	if filepath.Base(dir) == "..." {
+		dir = filepath.Dir(dir)
+	}
+
+	changed, err := diff.ChangedLines(dir, diffBase)
+	if err != nil {
+		log.Print(err)
+		return err
+	}
+
+	r := reporter.NewDiffReport(diff.NewCoverage(packageToFunctions, changed), diffBase, diffMinCov)
+
+	if outputFormat == formatJSON {
+		if err := reporter.WriteDiffJSON(os.Stdout, r); err != nil {
+			log.Print(err)
+			return err
+		}
+	} else {
+		cliL := reporter.NewCliTabLogger()
+		defer cliL.Close()
+
+		v := reporter.Verifier{Out: cliL}
+		v.VerifyDiffCoverage(r)
+	}
+
+	if !r.Pass {
+		return fmt.Errorf("changed statements failed to meet minimum coverage")
+	}
+
+	return nil
+}
+
+func validateOutputFlags() error {
+	if diffBase != "" && (outputFormat == formatJUnit || outputFile != "") {
+		return fmt.Errorf("diff-base only supports the %v and %v formats written to stdout", formatText, formatJSON)
+	}
+
+	if outputFormat == formatText {
+		if outputFile != "" {
+			return fmt.Errorf("output-file is not supported for the %v format", formatText)
+		}
+
+		return nil
+	}
+
+	if _, ok := reportWriters[outputFormat]; !ok {
+		return fmt.Errorf("unknown output format %v", outputFormat)
+	}
+
+	return nil
+}
+
+func writeReport(packageToFunctions map[string][]profile.FunctionCoverage, cfContent []byte) error {
+	v := reporter.Verifier{MinCov: minCov, MaxCRAP: maxCRAP, TotalMinCov: totalMinCov}
+
+	r, err := v.Report(packageToFunctions, cfContent)
+	if err != nil {
+		log.Print(err)
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			log.Printf("could not create output file %v %v", outputFile, err)
+			return err
+		}
+
+		defer func() {
+			if e := f.Close(); e != nil {
+				log.Print(e)
+			}
+		}()
+
+		out = f
+	}
+
+	if err := reportWriters[outputFormat](out, r); err != nil {
+		log.Print(err)
+		return err
+	}
+
+	if !r.Pass {
+		return fmt.Errorf("packages failed to meet minimum coverage")
+	}
+
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(checkCmd)
 
+	checkCmd.Flags().StringVar(
+		&outputFormat,
+		"format",
+		formatText,
+		fmt.Sprintf("output format for the coverage report (%v|%v|%v)", formatText, formatJSON, formatJUnit),
+	)
+
+	checkCmd.Flags().StringVarP(
+		&outputFile,
+		"output-file",
+		"o",
+		"",
+		"write the coverage report to this file instead of stdout (json and junit formats only)",
+	)
+
+	checkCmd.Flags().StringVar(
+		&diffBase,
+		"diff-base",
+		"",
+		"only check coverage of the lines changed since this git ref",
+	)
+
+	checkCmd.Flags().Float64Var(
+		&diffMinCov,
+		"diff-minimum-coverage",
+		0,
+		"minimum coverage percentage to enforce for changed statements when diff-base is set",
+	)
+
 	checkCmd.Flags().BoolVar(&printFunctions, "print-functions", false, "print coverage for individual functions")
 
+	checkCmd.Flags().BoolVar(
+		&printClosures,
+		"include-closures",
+		false,
+		"print coverage for the closures of each function along with print-functions",
+	)
+
 	checkCmd.Flags().BoolVar(
 		&printSrc,
 		"print-src",
@@ -132,6 +341,15 @@ func init() {
 		"print src coverage for each function (print-functions automatically set to true)",
 	)
 
+	checkCmd.Flags().BoolVar(
+		&printTree,
+		"print-tree",
+		false,
+		"print the coverage of every directory of packages as an indented tree",
+	)
+
+	checkCmd.Flags().BoolVar(&explain, "explain", false, "print the config rule which set the minimum for each package")
+
 	checkCmd.Flags().BoolVar(&noConfig, "no-config", false, "do not read configuration from file")
 
 	checkCmd.Flags().Float64VarP(
@@ -142,7 +360,31 @@ func init() {
 		"minimum coverage percentage to enforce for all packages (defaults to 0)",
 	)
 
-	checkCmd.Flags().StringVarP(&ProfileFile, "profile-file", "p", "", "path to coverage profile file")
+	checkCmd.Flags().Float64Var(
+		&totalMinCov,
+		"total-minimum",
+		0,
+		"minimum coverage percentage of every package together unless the config sets total_min_coverage_percentage",
+	)
+
+	checkCmd.Flags().Float64Var(
+		&maxCRAP,
+		"max-crap-score",
+		0,
+		"fail functions with a crap score above this unless the config sets max_crap_score (defaults to no maximum)",
+	)
+
+	checkCmd.Flags().StringSliceVarP(&ProfileFiles, "profile-file", "p", nil, profileFileUsage)
+
+	checkCmd.Flags().StringSliceVar(&coverDirs, "cover-dir", nil, coverDirUsage)
+	checkCmd.Flags().StringVar(&staleProfile, "stale-profile", staleFail, staleUsage)
+
+	checkCmd.Flags().BoolVar(
+		&coverPkgAll,
+		"coverpkg-all",
+		false,
+		"pass every project package to go test as -coverpkg so coverage from any test package counts",
+	)
 
 	checkCmd.Flags().StringVarP(
 		&configFile,
@@ -177,21 +419,62 @@ func getConfig() ([]byte, error) {
 	return cfContent, nil
 }
 
-func runTestsAndGenerateProfile(srcPath string) (*os.File, error) {
-	f, err := ioutil.TempFile("", "profile.out")
+// goTestFlags returns the test flags from the config file followed by the flags given after "--", so that the
+// command line wins over the config file. With --coverpkg-all the packages of the project files are passed as
+// -coverpkg so that tests in any package count towards every project package.
+func goTestFlags(projectFiles, testFlags []string) ([]string, error) {
+	cfContent, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := config.ConfigFile{}
+	if err := yaml.Unmarshal(cfContent, &cfg); err != nil {
+		return nil, fmt.Errorf("could not unmarshal yaml for config file %v", err)
+	}
+
+	flags := append(append([]string{}, cfg.TestFlags...), testFlags...)
+	if err := config.ValidateTestFlags(flags); err != nil {
+		return nil, err
+	}
+
+	if !coverPkgAll && !cfg.CoverPkgAll {
+		return flags, nil
+	}
+
+	if config.HasTestFlag(flags, "coverpkg") {
+		return nil, fmt.Errorf("-coverpkg can't be passed to go test together with --coverpkg-all")
+	}
+
+	pkgs, err := analyzer.PackagePaths(projectFiles)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve packages for -coverpkg %v", err)
+	}
+
+	return append(flags, "-coverpkg="+strings.Join(pkgs, ",")), nil
+}
+
+func runTestsAndGenerateProfile(srcPath string, projectFiles, testFlags []string) (*os.File, error) {
+	flags, err := goTestFlags(projectFiles, testFlags)
 	if err != nil {
 		return nil, err
 	}
 
-	args := []string{
-		"test",
-		"-coverprofile=" + f.Name(),
+	f, err := ioutil.TempFile("", "profile.out")
+	if err != nil {
+		return nil, err
 	}
 
+	args := []string{"test"}
+	args = append(args, flags...)
+	args = append(args, "-coverprofile="+f.Name())
+
 	pkgPath := srcPath
 	args = append(args, pkgPath)
 	c := exec.Command("go", args...)
 
+	log.Debugf("running %v", strings.Join(c.Args, " "))
+
 	stderr, err := c.StderrPipe()
 	if err != nil {
 		return nil, err
@@ -206,13 +489,19 @@ func runTestsAndGenerateProfile(srcPath string) (*os.File, error) {
 		return nil, err
 	}
 
+	// keep stdout clean for machine readable reports
+	var out io.Writer = os.Stdout
+	if outputFormat != formatText && outputFile == "" {
+		out = os.Stderr
+	}
+
 	var wg sync.WaitGroup
 
 	wg.Add(2)
 
-	go scan(&wg, stderr)
+	go scan(&wg, stderr, out)
 
-	go scan(&wg, stdout)
+	go scan(&wg, stdout, out)
 
 	if err := c.Wait(); err != nil {
 		return nil, err
@@ -223,12 +512,12 @@ func runTestsAndGenerateProfile(srcPath string) (*os.File, error) {
 	return f, nil
 }
 
-func scan(wg *sync.WaitGroup, r io.ReadCloser) {
+func scan(wg *sync.WaitGroup, r io.ReadCloser, w io.Writer) {
 	defer wg.Done()
 
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := scanner.Text()
-		fmt.Println(line)
+		fmt.Fprintln(w, line)
 	}
 }
@@ -15,15 +15,12 @@
 package cmd
 
 import (
-	"go/token"
 	"io/ioutil"
 	"log"
 	"os"
-	"strings"
 
 	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
 	"github.com/cvgw/gocheckcov/pkg/coverage/config"
-	"github.com/cvgw/gocheckcov/pkg/coverage/files"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 )
@@ -35,19 +32,7 @@ var checkInitCmd = &cobra.Command{
 	Long: `Create a new configuration file for gocheckcov which lists all of packages in the specified path and sets ` +
 		`the minimum converage percentage for each to the current coverage percentage for that package`,
 	Run: func(cmd *cobra.Command, args []string) {
-		profilePath := ProfileFile
-		fset := token.NewFileSet()
-
-		srcPath := files.SetSrcPath(args)
-		dir := srcPath
-		ignoreDirs := strings.Split(skipDirs, ",")
-		projectFiles, err := files.FilesForPath(dir, ignoreDirs)
-		if err != nil {
-			log.Printf("could not retrieve files for path %v %v", dir, err)
-			os.Exit(1)
-		}
-
-		packageToFunctions, err := analyzer.MapPackagesToFunctions(profilePath, projectFiles, fset)
+		packageToFunctions, err := mapPackagesForPath(args, ProfileFiles)
 		if err != nil {
 			log.Print(err)
 			os.Exit(1)
@@ -81,10 +66,8 @@ var checkInitCmd = &cobra.Command{
 func init() {
 	checkCmd.AddCommand(checkInitCmd)
 
-	checkInitCmd.Flags().StringVarP(&ProfileFile, "profile-file", "p", "", "path to coverage profile file")
+	checkInitCmd.Flags().StringSliceVarP(&ProfileFiles, "profile-file", "p", nil, profileFileUsage)
 
-	if err := checkInitCmd.MarkFlagRequired("profile-file"); err != nil {
-		log.Print(err)
-		os.Exit(1)
-	}
+	checkInitCmd.Flags().StringSliceVar(&coverDirs, "cover-dir", nil, coverDirUsage)
+	checkInitCmd.Flags().StringVar(&staleProfile, "stale-profile", staleWarn, staleUsage)
 }
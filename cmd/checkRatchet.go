@@ -0,0 +1,118 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/config"
+	"github.com/cvgw/gocheckcov/pkg/coverage/reporter"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// checkRatchetCmd represents the checkRatchet command
+var checkRatchetCmd = &cobra.Command{
+	Use:   "ratchet",
+	Short: "Raise the minimums in the config file to the current coverage",
+	Long: `Raise the minimum coverage percentage of each package rule in the configuration file to the current ` +
+		`coverage percentage of the packages it applies to. Minimums are never lowered and the file is rewritten in ` +
+		`place, keeping the order of its keys but not its comments`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCheckRatchetCommand(args); err != nil {
+			log.Print(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func runCheckRatchetCommand(args []string) error {
+	cfgPath := configFile
+	if cfgPath == "" {
+		cfgPath = config.DefaultConfigPath
+	}
+
+	cfContent, err := ioutil.ReadFile(cfgPath)
+	if err != nil {
+		return fmt.Errorf("could not read config file %v %v", cfgPath, err)
+	}
+
+	cfg := config.ConfigFile{}
+	if err := yaml.Unmarshal(cfContent, &cfg); err != nil {
+		return fmt.Errorf("could not unmarshal yaml for config file %v %v", cfgPath, err)
+	}
+
+	packageToFunctions, err := mapPackagesForPath(args, ProfileFiles)
+	if err != nil {
+		return err
+	}
+
+	r, err := reporter.Verifier{}.Report(packageToFunctions, cfContent)
+	if err != nil {
+		return err
+	}
+
+	coverages := make(map[string]float64, len(r.Packages))
+
+	for _, pkg := range r.Packages {
+		coverages[pkg.Path] = pkg.CoveragePercent
+	}
+
+	cliL := reporter.NewCliTabLogger()
+	defer cliL.Close()
+
+	changes := cfg.Ratchet(coverages)
+	if len(changes) == 0 {
+		cliL.Printf("no minimums were raised\n")
+		return nil
+	}
+
+	for _, c := range changes {
+		cliL.Printf("raised minimum for %v from %v%% to %v%%\n", c.Name, c.Old, c.New)
+	}
+
+	configContent, err := config.SetPackageMinimums(cfContent, changes)
+	if err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(cfgPath, configContent, fi.Mode()); err != nil {
+		return fmt.Errorf("could not write config file %v %v", cfgPath, err)
+	}
+
+	return nil
+}
+
+func init() {
+	checkCmd.AddCommand(checkRatchetCmd)
+
+	checkRatchetCmd.Flags().StringSliceVarP(&ProfileFiles, "profile-file", "p", nil, profileFileUsage)
+	checkRatchetCmd.Flags().StringVar(&staleProfile, "stale-profile", staleFail, staleUsage)
+
+	if err := checkRatchetCmd.MarkFlagRequired("profile-file"); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
+	checkRatchetCmd.Flags().StringVarP(&configFile, "config-file", "c", "", "path to configuration file")
+}
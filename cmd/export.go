@@ -0,0 +1,131 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/export"
+	"github.com/cvgw/gocheckcov/pkg/coverage/files"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exportFormatCobertura = "cobertura"
+	exportFormatLCOV      = "lcov"
+)
+
+var (
+	exportFormat     string
+	exportOutputFile string
+	exportCmd        = &cobra.Command{
+		Use:   "export",
+		Short: "Convert a coverage profile into another coverage format",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runExportCommand(args); err != nil {
+				log.Print(err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+type exportWriter func(io.Writer, map[string][]profile.FunctionCoverage, string) error
+
+var exportWriters = map[string]exportWriter{
+	exportFormatCobertura: export.WriteCobertura,
+	exportFormatLCOV:      export.WriteLCOV,
+}
+
+func runExportCommand(args []string) error {
+	if verbose {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	writer, ok := exportWriters[exportFormat]
+	if !ok {
+		return fmt.Errorf("unknown export format %v", exportFormat)
+	}
+
+	packageToFunctions, err := mapPackagesForPath(args, ProfileFiles)
+	if err != nil {
+		return err
+	}
+
+	sourceDir := files.SetSrcPath(args)
+	if filepath.Base(sourceDir) == "..." {
+		sourceDir = filepath.Dir(sourceDir)
+	}
+
+	var out io.Writer = os.Stdout
+
+	if exportOutputFile != "" {
+		f, err := os.Create(exportOutputFile)
+		if err != nil {
+			return fmt.Errorf("could not create output file %v %v", exportOutputFile, err)
+		}
+
+		defer func() {
+			if e := f.Close(); e != nil {
+				log.Print(e)
+			}
+		}()
+
+		out = f
+	}
+
+	return writer(out, packageToFunctions, sourceDir)
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(
+		&exportFormat,
+		"format",
+		exportFormatCobertura,
+		fmt.Sprintf("format to export coverage as (%v|%v)", exportFormatCobertura, exportFormatLCOV),
+	)
+
+	exportCmd.Flags().StringVarP(
+		&exportOutputFile,
+		"output-file",
+		"o",
+		"",
+		"write the exported coverage to this file instead of stdout",
+	)
+
+	exportCmd.Flags().StringSliceVarP(&ProfileFiles, "profile-file", "p", nil, profileFileUsage)
+	exportCmd.Flags().StringVar(&staleProfile, "stale-profile", staleWarn, staleUsage)
+
+	if err := exportCmd.MarkFlagRequired("profile-file"); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
+	exportCmd.Flags().StringVarP(
+		&skipDirs,
+		"skip-dirs",
+		"s",
+		"vendor",
+		"command separted list of directories to skip when reporting coverage",
+	)
+}
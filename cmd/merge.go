@@ -0,0 +1,86 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeOutputFile string
+	mergeCmd        = &cobra.Command{
+		Use:   "merge [profile files]",
+		Short: "Merge several coverage profiles into one",
+		Long: `Merge several coverage profiles into one. Arguments may be glob patterns.
+Block counts are summed for count and atomic profiles, for set profiles a block is covered if it is
+covered in any profile.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runMergeCommand(args); err != nil {
+				log.Print(err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func runMergeCommand(args []string) error {
+	if verbose {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	profiles, err := profile.ParseProfiles(args)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+
+	if mergeOutputFile != "" {
+		f, err := os.Create(mergeOutputFile)
+		if err != nil {
+			return fmt.Errorf("could not create output file %v %v", mergeOutputFile, err)
+		}
+
+		defer func() {
+			if e := f.Close(); e != nil {
+				log.Print(e)
+			}
+		}()
+
+		out = f
+	}
+
+	return profile.WriteProfiles(out, profiles)
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+
+	mergeCmd.Flags().StringVarP(
+		&mergeOutputFile,
+		"output-file",
+		"o",
+		"",
+		"write the merged profile to this file instead of stdout",
+	)
+}
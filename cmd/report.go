@@ -0,0 +1,109 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/reporter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportOutputDir string
+	reportCmd       = &cobra.Command{
+		Use:   "report",
+		Short: "Generate coverage reports",
+	}
+	reportHTMLCmd = &cobra.Command{
+		Use:   "html",
+		Short: "Generate a static html coverage report",
+		Long: `Generate a static html site with an index of packages and their minimum coverage, a table of functions ` +
+			`for each package, and the annotated source of each file`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runReportHTMLCommand(args); err != nil {
+				log.Print(err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func runReportHTMLCommand(args []string) error {
+	if verbose {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	packageToFunctions, err := mapPackagesForPath(args, ProfileFiles)
+	if err != nil {
+		return err
+	}
+
+	cfContent, err := getConfig()
+	if err != nil {
+		return err
+	}
+
+	v := reporter.Verifier{MinCov: minCov}
+
+	r, err := v.Report(packageToFunctions, cfContent)
+	if err != nil {
+		return err
+	}
+
+	return reporter.WriteHTML(reportOutputDir, r, packageToFunctions)
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportHTMLCmd)
+
+	reportHTMLCmd.Flags().StringVarP(&reportOutputDir, "output-dir", "o", "", "directory to write the html report to")
+
+	if err := reportHTMLCmd.MarkFlagRequired("output-dir"); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
+	reportHTMLCmd.Flags().StringSliceVarP(&ProfileFiles, "profile-file", "p", nil, profileFileUsage)
+	reportHTMLCmd.Flags().StringVar(&staleProfile, "stale-profile", staleWarn, staleUsage)
+
+	if err := reportHTMLCmd.MarkFlagRequired("profile-file"); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
+	reportHTMLCmd.Flags().BoolVar(&noConfig, "no-config", false, "do not read configuration from file")
+
+	reportHTMLCmd.Flags().Float64VarP(
+		&minCov,
+		"minimum-coverage",
+		"m",
+		0,
+		"minimum coverage percentage to enforce for all packages (defaults to 0)",
+	)
+
+	reportHTMLCmd.Flags().StringVarP(&configFile, "config-file", "c", "", "path to configuration file")
+
+	reportHTMLCmd.Flags().StringVarP(
+		&skipDirs,
+		"skip-dirs",
+		"s",
+		"vendor",
+		"command separted list of directories to skip when reporting coverage",
+	)
+}
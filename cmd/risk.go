@@ -0,0 +1,104 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/reporter"
+	"github.com/cvgw/gocheckcov/pkg/coverage/risk"
+	"github.com/spf13/cobra"
+)
+
+var (
+	riskTop    int
+	riskFormat string
+	riskCmd    = &cobra.Command{
+		Use:   "risk [path]",
+		Short: "Rank functions by CRAP score, which combines cyclomatic complexity with coverage",
+		Long: `Rank functions by CRAP score, complexity² × (1 − coverage)³ + complexity, so that complex functions ` +
+			`without coverage come first and covered or trivial functions last`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runRiskCommand(args); err != nil {
+				log.Print(err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func runRiskCommand(args []string) error {
+	if verbose {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	if riskFormat != formatText && riskFormat != formatJSON {
+		return fmt.Errorf("unknown output format %v", riskFormat)
+	}
+
+	packageToFunctions, err := mapPackagesForPath(args, ProfileFiles)
+	if err != nil {
+		return err
+	}
+
+	ranked := risk.Rank(packageToFunctions)
+	if riskTop > 0 && len(ranked) > riskTop {
+		ranked = ranked[:riskTop]
+	}
+
+	if riskFormat == formatJSON {
+		return reporter.WriteRiskJSON(os.Stdout, ranked)
+	}
+
+	cliL := reporter.NewCliTabLogger()
+	defer cliL.Close()
+
+	v := reporter.Verifier{Out: cliL}
+	v.PrintRisk(ranked)
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(riskCmd)
+
+	riskCmd.Flags().IntVarP(&riskTop, "top", "n", 20, "number of functions to list, 0 lists every function")
+
+	riskCmd.Flags().StringVar(
+		&riskFormat,
+		"format",
+		formatText,
+		fmt.Sprintf("output format for the ranking (%v|%v)", formatText, formatJSON),
+	)
+
+	riskCmd.Flags().StringSliceVarP(&ProfileFiles, "profile-file", "p", nil, profileFileUsage)
+	riskCmd.Flags().StringVar(&staleProfile, "stale-profile", staleWarn, staleUsage)
+
+	if err := riskCmd.MarkFlagRequired("profile-file"); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
+	riskCmd.Flags().StringVarP(
+		&skipDirs,
+		"skip-dirs",
+		"s",
+		"vendor",
+		"command separted list of directories to skip when reporting coverage",
+	)
+}
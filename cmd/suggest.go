@@ -0,0 +1,122 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/reporter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestTop    int
+	suggestFormat string
+	suggestCmd    = &cobra.Command{
+		Use:   "suggest <pkg> [path]",
+		Short: "Suggest the functions to test first to raise the coverage of a package",
+		Long: `List the functions of the package with the most uncovered statements first, along with the number of ` +
+			`statements which must be covered for the package to reach its minimum coverage`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runSuggestCommand(args[0], args[1:]); err != nil {
+				log.Print(err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func runSuggestCommand(pkg string, args []string) error {
+	if verbose {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	if suggestFormat != formatText && suggestFormat != formatJSON {
+		return fmt.Errorf("unknown output format %v", suggestFormat)
+	}
+
+	packageToFunctions, err := mapPackagesForPath(args, ProfileFiles)
+	if err != nil {
+		return err
+	}
+
+	cfContent, err := getConfig()
+	if err != nil {
+		return err
+	}
+
+	v := reporter.Verifier{MinCov: minCov}
+
+	plan, err := v.Suggest(pkg, packageToFunctions, cfContent)
+	if err != nil {
+		return err
+	}
+
+	if suggestFormat == formatJSON {
+		return reporter.WriteSuggestJSON(os.Stdout, plan)
+	}
+
+	cliL := reporter.NewCliTabLogger()
+	defer cliL.Close()
+
+	v.Out = cliL
+	v.PrintSuggestions(plan, suggestTop)
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(suggestCmd)
+
+	suggestCmd.Flags().IntVarP(&suggestTop, "top", "n", 10, "number of functions to list, 0 lists every function")
+
+	suggestCmd.Flags().StringVar(
+		&suggestFormat,
+		"format",
+		formatText,
+		fmt.Sprintf("output format for the suggestions (%v|%v)", formatText, formatJSON),
+	)
+
+	suggestCmd.Flags().Float64VarP(
+		&minCov,
+		"minimum-coverage",
+		"m",
+		0,
+		"minimum coverage percentage of the package when there is no configuration file (defaults to 0)",
+	)
+
+	suggestCmd.Flags().StringVarP(&configFile, "config-file", "c", "", "path to configuration file")
+	suggestCmd.Flags().BoolVar(&noConfig, "no-config", false, "do not read configuration from file")
+
+	suggestCmd.Flags().StringSliceVarP(&ProfileFiles, "profile-file", "p", nil, profileFileUsage)
+	suggestCmd.Flags().StringVar(&staleProfile, "stale-profile", staleWarn, staleUsage)
+
+	if err := suggestCmd.MarkFlagRequired("profile-file"); err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
+	suggestCmd.Flags().StringVarP(
+		&skipDirs,
+		"skip-dirs",
+		"s",
+		"vendor",
+		"command separted list of directories to skip when reporting coverage",
+	)
+}
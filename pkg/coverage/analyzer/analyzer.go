@@ -15,18 +15,17 @@
 package analyzer
 
 import (
-	"context"
 	"fmt"
 	"go/token"
 	"math"
-	"path/filepath"
+	"runtime"
+	"sync"
 
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser"
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/tools/cover"
-	"golang.org/x/tools/go/packages"
 )
 
 type PackageCoverages struct {
@@ -38,6 +37,15 @@ type coverage struct {
 	ExecutedCount   int64
 	CoveragePercent float64
 	Functions       []profile.FunctionCoverage
+	// BranchCount and CoveredBranchCount sum the branches of the functions, BranchCoveragePercent is 100 for a package
+	// without branches
+	BranchCount           int64
+	CoveredBranchCount    int64
+	BranchCoveragePercent float64
+	// Module is the path of the module the package belongs to, empty outside of modules
+	Module string
+	// Untested is true when the package has functions and none of them are tested
+	Untested bool
 }
 
 func (p *PackageCoverages) Coverage(pkg string) (coverage, bool) {
@@ -53,24 +61,25 @@ func NewPackageCoverages(packagesToFunctions map[string][]profile.FunctionCovera
 
 		var executedCount int64
 
+		var branchCount, coveredBranchCount int64
+
 		for _, function := range functions {
 			statementCount += function.StatementCount
 			executedCount += function.CoveredCount
-		}
-
-		var covPer float64
-
-		if executedCount == 0 && statementCount == 0 {
-			covPer = 100
-		} else {
-			covPer = math.Floor((float64(executedCount)/float64(statementCount))*10000) / 100
+			branchCount += function.BranchCount
+			coveredBranchCount += function.CoveredBranchCount
 		}
 
 		c := coverage{
-			StatementCount:  statementCount,
-			ExecutedCount:   executedCount,
-			CoveragePercent: covPer,
-			Functions:       functions,
+			StatementCount:        statementCount,
+			ExecutedCount:         executedCount,
+			CoveragePercent:       CoveragePercent(executedCount, statementCount),
+			Functions:             functions,
+			BranchCount:           branchCount,
+			CoveredBranchCount:    coveredBranchCount,
+			BranchCoveragePercent: CoveragePercent(coveredBranchCount, branchCount),
+			Module:                packageModule(functions),
+			Untested:              untested(functions),
 		}
 		pkgToCoverage[pkg] = c
 	}
@@ -80,46 +89,14 @@ func NewPackageCoverages(packagesToFunctions map[string][]profile.FunctionCovera
 	}
 }
 
-type packageList struct {
-	cache map[string]*pkg
-}
-
-type pkg struct {
-	*packages.Package
-}
-
-func (p *pkg) Path() string {
-	return p.PkgPath
-}
-
-func (p *packageList) get(dirPath string) (*pkg, error) {
-	if p.cache == nil {
-		p.cache = make(map[string]*pkg)
+// CoveragePercent returns covered as a percentage of total, rounded down to two decimal places. Nothing to cover
+// counts as fully covered.
+func CoveragePercent(covered, total int64) float64 {
+	if total == 0 {
+		return 100
 	}
 
-	bPkg, ok := p.cache[dirPath]
-	if !ok {
-		conf := &packages.Config{
-			Mode:    packages.NeedName,
-			Tests:   false,
-			Context: context.Background(),
-		}
-
-		pkgs, err := packages.Load(conf, dirPath)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(pkgs) != 1 {
-			return nil, fmt.Errorf("expected 1 pkg")
-		}
-
-		pk := pkgs[0]
-		bPkg = &pkg{pk}
-		p.cache[dirPath] = bPkg
-	}
-
-	return bPkg, nil
+	return math.Floor((float64(covered)/float64(total))*10000) / 100
 }
 
 func MapPackagesToFunctions(
@@ -133,6 +110,16 @@ func MapPackagesToFunctions(
 		return nil, e
 	}
 
+	return MapProfilesToFunctions(profiles, projectFiles, fset)
+}
+
+// MapProfilesToFunctions maps the functions of each project file to its package and records their coverage from
+// profiles. Files are parsed concurrently, the functions of each package keep the order of projectFiles.
+func MapProfilesToFunctions(
+	profiles []*cover.Profile,
+	projectFiles []string,
+	fset *token.FileSet,
+) (map[string][]profile.FunctionCoverage, error) {
 	filePathToProfileMap := make(map[string]*cover.Profile)
 
 	for _, prof := range profiles {
@@ -142,49 +129,141 @@ func MapPackagesToFunctions(
 		filePathToProfileMap[pPath] = prof
 	}
 
-	packageToFunctions := make(map[string][]profile.FunctionCoverage)
+	packageIndex, err := loadPackageIndex(projectFiles)
+	if err != nil {
+		return nil, err
+	}
 
-	packageList := &packageList{}
+	results := make([]fileCoverage, len(projectFiles))
+	jobs := make(chan int)
 
-	for _, filePath := range projectFiles {
-		node, err := goparser.NodeFromFilePath(filePath, fset)
-		if err != nil {
-			e := fmt.Errorf("could not retrieve node from filepath %v", err)
-			return nil, e
-		}
+	var wg sync.WaitGroup
+
+	for w := 0; w < workerCount(len(projectFiles)); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
 
-		functions, err := functions.CollectFunctions(node, fset, filePath)
-		if err != nil {
-			e := fmt.Errorf("could not collect functions for filepath %v %v", filePath, err)
-			return nil, e
+			for i := range jobs {
+				results[i] = mapFileToFunctions(projectFiles[i], fset, packageIndex, filePathToProfileMap)
+			}
+		}()
+	}
+
+	for i := range projectFiles {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	packageToFunctions := make(map[string][]profile.FunctionCoverage)
+
+	for _, res := range results {
+		if res.err != nil {
+			return nil, res.err
 		}
 
-		log.Debugf("functions for file %v %v", filePath, functions)
+		packageToFunctions[res.pkg] = append(packageToFunctions[res.pkg], res.functions...)
+	}
 
-		pkg, err := packageList.get(filepath.Dir(filePath))
-		if err != nil {
-			return nil, err
+	log.Debugf("map of packages to functions %v", packageToFunctions)
+
+	return packageToFunctions, nil
+}
+
+// fileCoverage is the coverage of the functions of a single file
+type fileCoverage struct {
+	pkg       string
+	functions []profile.FunctionCoverage
+	err       error
+}
+
+// workerCount returns the number of files to parse concurrently
+func workerCount(files int) int {
+	workers := runtime.GOMAXPROCS(0)
+	if files < workers {
+		workers = files
+	}
+
+	return workers
+}
+
+func mapFileToFunctions(
+	filePath string,
+	fset *token.FileSet,
+	packageIndex *packageIndex,
+	filePathToProfileMap map[string]*cover.Profile,
+) fileCoverage {
+	node, err := goparser.NodeFromFilePath(filePath, fset)
+	if err != nil {
+		return fileCoverage{err: fmt.Errorf("could not retrieve node from filepath %v", err)}
+	}
+
+	functions, err := functions.CollectFunctions(node, fset, filePath)
+	if err != nil {
+		return fileCoverage{err: fmt.Errorf("could not collect functions for filepath %v %v", filePath, err)}
+	}
+
+	log.Debugf("functions for file %v %v", filePath, functions)
+
+	for _, fn := range functions {
+		for _, r := range fn.Ignored {
+			log.Debugf("ignoring %v:%v-%v of function %v %v", filePath, r.StartLine, r.EndLine, fn.Name, r.Reason)
 		}
+	}
 
-		log.Debugf("found pkg %v for filepath %v", pkg.Path(), filePath)
+	pkg, err := packageIndex.packageForFile(filePath)
+	if err != nil {
+		return fileCoverage{err: err}
+	}
 
-		var funcCoverages []profile.FunctionCoverage
+	log.Debugf("found pkg %v for filepath %v", pkg.PkgPath, filePath)
 
-		p := profile.Parser{FilePath: filePath, Fset: fset}
+	p := profile.Parser{FilePath: filePath, Fset: fset}
 
-		profilePath := fmt.Sprintf("%v/%v", pkg.Path(), filepath.Base(filePath))
+	for _, profilePath := range profileKeys(pkg, filePath) {
 		if prof, ok := filePathToProfileMap[profilePath]; ok {
 			p.Profile = prof
-		} else {
-			log.Debugf("no profile found for path %v", profilePath)
+			break
 		}
 
-		funcCoverages = p.RecordFunctionCoverage(functions)
+		log.Debugf("no profile found for path %v", profilePath)
+	}
+
+	fcs := p.RecordFunctionCoverage(functions)
+	untested := !packageIndex.hasTests(filePath)
 
-		packageToFunctions[pkg.Path()] = append(packageToFunctions[pkg.Path()], funcCoverages...)
+	for i := range fcs {
+		fcs[i].Untested = untested
+
+		if pkg.Module != nil {
+			fcs[i].Module = pkg.Module.Path
+		}
 	}
 
-	log.Debugf("map of packages to functions %v", packageToFunctions)
+	return fileCoverage{pkg: pkg.PkgPath, functions: fcs}
+}
 
-	return packageToFunctions, nil
+// packageModule returns the module of the first function which has one
+func packageModule(functions []profile.FunctionCoverage) string {
+	for _, fc := range functions {
+		if fc.Module != "" {
+			return fc.Module
+		}
+	}
+
+	return ""
+}
+
+// untested reports whether there are functions and none of them are tested
+func untested(functions []profile.FunctionCoverage) bool {
+	for _, fc := range functions {
+		if !fc.Untested {
+			return false
+		}
+	}
+
+	return len(functions) > 0
 }
@@ -21,8 +21,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
 	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
 )
 
 func Test_NewPackageCoverages(t *testing.T) {
@@ -73,6 +76,90 @@ func Test_PackageCoverages_Coverage(t *testing.T) {
 	g.Expect(cov.CoveragePercent).To(Equal(float64(100)))
 }
 
+func Test_CoveragePercent(t *testing.T) {
+	type testcase struct {
+		description string
+		covered     int64
+		total       int64
+		expected    float64
+	}
+
+	testCases := []testcase{
+		{description: "nothing to cover", expected: 100},
+		{description: "uncovered", total: 4, expected: 0},
+		{description: "rounded down to two decimal places", covered: 2, total: 3, expected: 66.66},
+		{description: "fully covered", covered: 3, total: 3, expected: 100},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(CoveragePercent(tc.covered, tc.total)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_NewPackageCoverages_Untested(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	p := NewPackageCoverages(map[string][]profile.FunctionCoverage{
+		"foo/tested": []profile.FunctionCoverage{
+			{StatementCount: 2},
+		},
+		"foo/untested": []profile.FunctionCoverage{
+			{StatementCount: 2, Untested: true},
+			{StatementCount: 1, Untested: true},
+		},
+		"foo/empty": []profile.FunctionCoverage{},
+	})
+
+	for pkg, untested := range map[string]bool{"foo/tested": false, "foo/untested": true, "foo/empty": false} {
+		cov, ok := p.Coverage(pkg)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(cov.Untested).To(Equal(untested), pkg)
+	}
+}
+
+func Test_StaleFiles(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	function := func(path string) functions.Function {
+		return functions.Function{
+			Name:       "Meow",
+			SrcPath:    path,
+			StartLine:  3,
+			StartCol:   1,
+			EndLine:    8,
+			EndCol:     2,
+			Statements: []statements.Statement{{StartLine: 4, StartCol: 2, EndLine: 6, EndCol: 3}},
+		}
+	}
+
+	prof := &cover.Profile{
+		Blocks: []cover.ProfileBlock{{StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 10, NumStmt: 1}},
+	}
+	staleProf := &cover.Profile{
+		Blocks: []cover.ProfileBlock{{StartLine: 5, StartCol: 2, EndLine: 5, EndCol: 10, NumStmt: 1}},
+	}
+
+	stale := StaleFiles(map[string][]profile.FunctionCoverage{
+		"foo": []profile.FunctionCoverage{
+			{Function: function("/src/foo/b.go"), Profile: staleProf},
+			{Function: function("/src/foo/a.go"), Profile: prof},
+		},
+		"bar": []profile.FunctionCoverage{
+			{Function: function("/src/bar/a.go"), Profile: staleProf},
+			{Function: function("/src/bar/untested.go")},
+		},
+	})
+
+	g.Expect(stale).To(HaveLen(2))
+	g.Expect(stale[0].Path).To(Equal("/src/bar/a.go"))
+	g.Expect(stale[1].Path).To(Equal("/src/foo/b.go"))
+	g.Expect(stale[1].Blocks).To(Equal(staleProf.Blocks))
+}
+
 func Test_MapPackagesToFunctions(t *testing.T) {
 	type testcase struct {
 		srcPath   string
@@ -0,0 +1,141 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+// linesPerFunction is the number of lines of each function written by writeSyntheticRepo
+const linesPerFunction = 8
+
+// writeSyntheticRepo writes a module with the given number of packages, files per package and functions per file. It
+// returns the paths of the source files and a profile covering half of the branches of every function.
+func writeSyntheticRepo(tb testing.TB, dir string, pkgCount, fileCount, funcCount int) ([]string, []*cover.Profile) {
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/bench\n"), 0644); err != nil {
+		tb.Fatal(err)
+	}
+
+	projectFiles := make([]string, 0, pkgCount*fileCount)
+	profiles := make([]*cover.Profile, 0, pkgCount*fileCount)
+
+	for p := 0; p < pkgCount; p++ {
+		pkgDir := filepath.Join(dir, fmt.Sprintf("pkg%d", p))
+		if err := os.MkdirAll(pkgDir, 0755); err != nil {
+			tb.Fatal(err)
+		}
+
+		for f := 0; f < fileCount; f++ {
+			name := fmt.Sprintf("file%d.go", f)
+			src := bytes.NewBufferString(fmt.Sprintf("package pkg%d\n", p))
+			prof := &cover.Profile{FileName: fmt.Sprintf("example.com/bench/pkg%d/%v", p, name), Mode: "set"}
+
+			for fn := 0; fn < funcCount; fn++ {
+				// the first line of the file is the package clause and each function is preceded by a blank line
+				line := 3 + fn*linesPerFunction
+				fmt.Fprintf(src, "\nfunc F%dx%d(x int) int {\n\tif x > %d {\n\t\treturn x\n\t}\n\n\treturn -x\n}\n", f, fn, fn)
+
+				prof.Blocks = append(prof.Blocks,
+					cover.ProfileBlock{StartLine: line, StartCol: 24, EndLine: line + 1, EndCol: 12, NumStmt: 1, Count: 1},
+					cover.ProfileBlock{StartLine: line + 1, StartCol: 12, EndLine: line + 3, EndCol: 3, NumStmt: 1},
+					cover.ProfileBlock{StartLine: line + 5, StartCol: 2, EndLine: line + 5, EndCol: 11, NumStmt: 1, Count: 1},
+				)
+			}
+
+			path := filepath.Join(pkgDir, name)
+			if err := ioutil.WriteFile(path, src.Bytes(), 0644); err != nil {
+				tb.Fatal(err)
+			}
+
+			projectFiles = append(projectFiles, path)
+			profiles = append(profiles, prof)
+		}
+	}
+
+	return projectFiles, profiles
+}
+
+func Benchmark_MapProfilesToFunctions(b *testing.B) {
+	sizes := []struct {
+		pkgs, files, funcs int
+	}{
+		{pkgs: 5, files: 5, funcs: 20},
+		{pkgs: 20, files: 10, funcs: 50},
+	}
+
+	defer setModuleEnv(b)()
+
+	for _, size := range sizes {
+		size := size
+
+		b.Run(fmt.Sprintf("pkgs=%d/files=%d/funcs=%d", size.pkgs, size.files, size.funcs), func(b *testing.B) {
+			dir, err := ioutil.TempDir("", "bench")
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			defer os.RemoveAll(dir)
+
+			projectFiles, profiles := writeSyntheticRepo(b, dir, size.pkgs, size.files, size.funcs)
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := MapProfilesToFunctions(profiles, projectFiles, token.NewFileSet()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func Test_MapProfilesToFunctions_SyntheticRepo(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	defer setModuleEnv(t)()
+
+	dir, err := ioutil.TempDir("", "synthetic")
+	g.Expect(err).To(BeNil())
+
+	defer os.RemoveAll(dir)
+
+	projectFiles, profiles := writeSyntheticRepo(t, dir, 3, 2, 4)
+
+	res, err := MapProfilesToFunctions(profiles, projectFiles, token.NewFileSet())
+	g.Expect(err).To(BeNil())
+
+	pc := NewPackageCoverages(res)
+
+	for p := 0; p < 3; p++ {
+		cov, ok := pc.Coverage(fmt.Sprintf("example.com/bench/pkg%d", p))
+		g.Expect(ok).To(BeTrue())
+		g.Expect(cov.StatementCount).To(Equal(int64(24)))
+		g.Expect(cov.ExecutedCount).To(Equal(int64(16)))
+
+		// functions keep the order of the project files
+		g.Expect(cov.Functions).To(HaveLen(8))
+		g.Expect(cov.Functions[0].Name).To(Equal("F0x0"))
+		g.Expect(cov.Functions[4].Name).To(Equal("F1x0"))
+	}
+}
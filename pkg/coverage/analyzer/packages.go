@@ -0,0 +1,250 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/tools/go/packages"
+)
+
+// packageIndex maps source files to the package they belong to and records which package directories have test files
+type packageIndex struct {
+	files    map[string]*packages.Package
+	dirs     map[string]*packages.Package
+	testDirs map[string]bool
+}
+
+// loadPackageIndex loads the packages of the project files. The directories of the files are grouped by the module
+// they belong to and the packages of each module are loaded with a single call from the module root, so nested
+// modules, replace directives and go.work workspaces resolve the same way they do for go test. Directories outside of
+// any module are loaded together from the working directory. Test variants of the packages are loaded as well so the
+// directories with test files are known without reading them again.
+func loadPackageIndex(projectFiles []string) (*packageIndex, error) {
+	moduleDirs := make(map[string][]string)
+	seen := make(map[string]bool)
+
+	for _, filePath := range projectFiles {
+		dir, err := filepath.Abs(filepath.Dir(filePath))
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[dir] {
+			continue
+		}
+
+		seen[dir] = true
+		root := moduleRoot(dir)
+		moduleDirs[root] = append(moduleDirs[root], dir)
+	}
+
+	idx := &packageIndex{
+		files:    make(map[string]*packages.Package),
+		dirs:     make(map[string]*packages.Package),
+		testDirs: make(map[string]bool),
+	}
+
+	roots := make([]string, 0, len(moduleDirs))
+	for root := range moduleDirs {
+		roots = append(roots, root)
+	}
+
+	sort.Strings(roots)
+
+	for _, root := range roots {
+		conf := &packages.Config{
+			Mode:    packages.NeedName | packages.NeedFiles | packages.NeedModule,
+			Tests:   true,
+			Context: context.Background(),
+			Dir:     root,
+		}
+
+		pkgs, err := packages.Load(conf, moduleDirs[root]...)
+		if err != nil {
+			return nil, fmt.Errorf("could not load packages for module at %v %v", root, err)
+		}
+
+		for _, pkg := range pkgs {
+			idx.add(pkg)
+		}
+
+		if err := idx.loadUnresolved(conf, moduleDirs[root]); err != nil {
+			return nil, err
+		}
+	}
+
+	return idx, nil
+}
+
+// PackagePaths returns the sorted import paths of the packages the project files belong to
+func PackagePaths(projectFiles []string) ([]string, error) {
+	idx, err := loadPackageIndex(projectFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	paths := make([]string, 0)
+
+	for _, filePath := range projectFiles {
+		pkg, err := idx.packageForFile(filePath)
+		if err != nil {
+			log.Debug(err)
+			continue
+		}
+
+		if !seen[pkg.PkgPath] {
+			seen[pkg.PkgPath] = true
+			paths = append(paths, pkg.PkgPath)
+		}
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// loadUnresolved loads each directory which did not resolve to a package with its files on its own, for example
+// directories outside of GOPATH when not using modules
+func (idx *packageIndex) loadUnresolved(conf *packages.Config, dirs []string) error {
+	for _, dir := range dirs {
+		if _, ok := idx.dirs[dir]; ok {
+			continue
+		}
+
+		log.Debugf("loading unresolved package dir %v", dir)
+
+		pkgs, err := packages.Load(conf, dir)
+		if err != nil {
+			return err
+		}
+
+		found := make([]*packages.Package, 0, 1)
+
+		for _, pkg := range pkgs {
+			if isTestPackage(pkg) {
+				idx.addTests(pkg)
+				continue
+			}
+
+			found = append(found, pkg)
+		}
+
+		if len(found) != 1 {
+			return fmt.Errorf("expected 1 pkg for dir %v", dir)
+		}
+
+		idx.dirs[dir] = found[0]
+	}
+
+	return nil
+}
+
+func (idx *packageIndex) add(pkg *packages.Package) {
+	if isTestPackage(pkg) {
+		idx.addTests(pkg)
+		return
+	}
+
+	if pkg.PkgPath == "" {
+		log.Debugf("skipping package without an import path %v", pkg.ID)
+		return
+	}
+
+	for _, file := range pkg.GoFiles {
+		idx.files[file] = pkg
+		idx.dirs[filepath.Dir(file)] = pkg
+	}
+}
+
+// addTests records the directories of the test files of a test package
+func (idx *packageIndex) addTests(pkg *packages.Package) {
+	for _, file := range pkg.GoFiles {
+		if strings.HasSuffix(file, "_test.go") {
+			idx.testDirs[filepath.Dir(file)] = true
+		}
+	}
+}
+
+// hasTests reports whether the directory of the file has test files
+func (idx *packageIndex) hasTests(filePath string) bool {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		log.Debug(err)
+		return false
+	}
+
+	return idx.testDirs[filepath.Dir(abs)]
+}
+
+// isTestPackage reports whether pkg is a test variant of a package, such as "foo [foo.test]" or "foo_test
+// [foo.test]", or the generated main package "foo.test" of a test binary
+func isTestPackage(pkg *packages.Package) bool {
+	return pkg.ID != pkg.PkgPath || strings.HasSuffix(pkg.ID, ".test")
+}
+
+// packageForFile returns the package of the file. Files which are not part of the build, such as files excluded by
+// build constraints, belong to the package of their directory.
+func (idx *packageIndex) packageForFile(filePath string) (*packages.Package, error) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkg, ok := idx.files[abs]; ok {
+		return pkg, nil
+	}
+
+	if pkg, ok := idx.dirs[filepath.Dir(abs)]; ok {
+		return pkg, nil
+	}
+
+	return nil, fmt.Errorf("could not find package for file %v", filePath)
+}
+
+// moduleRoot returns the directory of the nearest go.mod at or above dir, or an empty string if there is none
+func moduleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// profileKeys returns the names a profile may use for a file of pkg. go test names files by the import path of their
+// package, while profiles of packages outside of a module or GOPATH use the absolute path of the file.
+func profileKeys(pkg *packages.Package, filePath string) []string {
+	keys := []string{fmt.Sprintf("%v/%v", pkg.PkgPath, filepath.Base(filePath))}
+
+	if abs, err := filepath.Abs(filePath); err == nil {
+		keys = append(keys, abs)
+	}
+
+	return keys
+}
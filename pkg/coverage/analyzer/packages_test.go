@@ -0,0 +1,206 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+func Test_MapProfilesToFunctions_NestedModules(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	defer setModuleEnv(t)()
+
+	dir, err := ioutil.TempDir("", "modules")
+	g.Expect(err).To(BeNil())
+
+	defer os.RemoveAll(dir)
+
+	srcFiles := map[string]string{
+		"go.mod":         "module example.com/root\n",
+		"a/a.go":         "package a\n\nfunc A() int {\n\treturn 1\n}\n",
+		"sub/go.mod":     "module example.com/sub\n",
+		"sub/b/b.go":     "package b\n\nfunc B() int {\n\treturn 2\n}\n",
+		"sub/b/other.go": "// +build ignore\n\npackage b\n\nfunc C() int {\n\treturn 3\n}\n",
+	}
+
+	for name, content := range srcFiles {
+		path := filepath.Join(dir, name)
+		g.Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		g.Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	}
+
+	block := cover.ProfileBlock{StartLine: 3, StartCol: 14, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 1}
+	profiles := []*cover.Profile{
+		{FileName: "example.com/root/a/a.go", Mode: "set", Blocks: []cover.ProfileBlock{block}},
+		{FileName: "example.com/sub/b/b.go", Mode: "set", Blocks: []cover.ProfileBlock{block}},
+	}
+
+	projectFiles := []string{
+		filepath.Join(dir, "a", "a.go"),
+		filepath.Join(dir, "sub", "b", "b.go"),
+		filepath.Join(dir, "sub", "b", "other.go"),
+	}
+
+	res, err := MapProfilesToFunctions(profiles, projectFiles, token.NewFileSet())
+	g.Expect(err).To(BeNil())
+	g.Expect(res).To(HaveLen(2))
+	g.Expect(res).To(HaveKey("example.com/root/a"))
+	g.Expect(res).To(HaveKey("example.com/sub/b"))
+
+	g.Expect(res["example.com/root/a"]).To(HaveLen(1))
+	g.Expect(res["example.com/root/a"][0].CoveredCount).To(Equal(int64(1)))
+
+	g.Expect(res["example.com/sub/b"]).To(HaveLen(2))
+	g.Expect(res["example.com/sub/b"][0].CoveredCount).To(Equal(int64(1)))
+}
+
+func Test_MapProfilesToFunctions_Untested(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	defer setModuleEnv(t)()
+
+	dir, err := ioutil.TempDir("", "untested")
+	g.Expect(err).To(BeNil())
+
+	defer os.RemoveAll(dir)
+
+	srcFiles := map[string]string{
+		"go.mod":                 "module example.com/app\n",
+		"tested/a.go":            "package tested\n\nfunc A() int {\n\treturn 1\n}\n",
+		"tested/a_test.go":       "package tested\n",
+		"external/b.go":          "package external\n\nfunc B() int {\n\treturn 2\n}\n",
+		"external/b_test.go":     "package external_test\n",
+		"untested/c.go":          "package untested\n\nfunc C() int {\n\treturn 3\n}\n",
+		"untested/sub/d_test.go": "package sub\n",
+		"untested/sub/doc.go":    "package sub\n",
+	}
+
+	projectFiles := make([]string, 0, len(srcFiles))
+
+	for name, content := range srcFiles {
+		path := filepath.Join(dir, name)
+		g.Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		g.Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+
+		if filepath.Ext(name) == ".go" && !strings.HasSuffix(name, "_test.go") {
+			projectFiles = append(projectFiles, path)
+		}
+	}
+
+	res, err := MapProfilesToFunctions(nil, projectFiles, token.NewFileSet())
+	g.Expect(err).To(BeNil())
+
+	for pkg, untested := range map[string]bool{
+		"example.com/app/tested":   false,
+		"example.com/app/external": false,
+		"example.com/app/untested": true,
+	} {
+		g.Expect(res[pkg]).To(HaveLen(1), pkg)
+		g.Expect(res[pkg][0].Untested).To(Equal(untested), pkg)
+	}
+}
+
+func Test_PackagePaths(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	defer setModuleEnv(t)()
+
+	dir, err := ioutil.TempDir("", "packages")
+	g.Expect(err).To(BeNil())
+
+	defer os.RemoveAll(dir)
+
+	srcFiles := map[string]string{
+		"go.mod":                "module example.com/app\n",
+		"internal/store/a.go":   "package store\n",
+		"internal/store/b.go":   "package store\n",
+		"cmd/app/main.go":       "package main\n",
+		"test/integration/a.go": "package integration\n",
+	}
+
+	projectFiles := make([]string, 0, len(srcFiles))
+
+	for name, content := range srcFiles {
+		path := filepath.Join(dir, name)
+		g.Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		g.Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+
+		if filepath.Ext(name) == ".go" {
+			projectFiles = append(projectFiles, path)
+		}
+	}
+
+	paths, err := PackagePaths(projectFiles)
+	g.Expect(err).To(BeNil())
+	g.Expect(paths).To(Equal([]string{
+		"example.com/app/cmd/app",
+		"example.com/app/internal/store",
+		"example.com/app/test/integration",
+	}))
+}
+
+func Test_moduleRoot(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir, err := ioutil.TempDir("", "modules")
+	g.Expect(err).To(BeNil())
+
+	defer os.RemoveAll(dir)
+
+	nested := filepath.Join(dir, "sub", "pkg")
+	g.Expect(os.MkdirAll(nested, 0755)).To(Succeed())
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, "sub", "go.mod"), []byte("module example.com/sub\n"), 0644)).To(Succeed())
+
+	g.Expect(moduleRoot(nested)).To(Equal(filepath.Join(dir, "sub")))
+	g.Expect(moduleRoot(filepath.Join(dir, "sub"))).To(Equal(filepath.Join(dir, "sub")))
+}
+
+// setModuleEnv sets the environment for go list to load packages in module mode and returns a function which restores
+// the previous environment
+func setModuleEnv(tb testing.TB) func() {
+	restore := []func(){}
+
+	for key, value := range map[string]string{"GO111MODULE": "on", "GOFLAGS": "", "GOWORK": "off"} {
+		prev, ok := os.LookupEnv(key)
+		if err := os.Setenv(key, value); err != nil {
+			tb.Fatal(err)
+		}
+
+		key := key
+
+		restore = append(restore, func() {
+			if ok {
+				os.Setenv(key, prev)
+			} else {
+				os.Unsetenv(key)
+			}
+		})
+	}
+
+	return func() {
+		for _, r := range restore {
+			r()
+		}
+	}
+}
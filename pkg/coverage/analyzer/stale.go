@@ -0,0 +1,62 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"golang.org/x/tools/cover"
+)
+
+// StaleFile is a source file which changed after its profile was generated
+type StaleFile struct {
+	Path string
+	// Blocks are the profile blocks which no longer line up with the statements of the file
+	Blocks []cover.ProfileBlock
+}
+
+// StaleFiles returns the files, sorted by path, whose profile blocks do not line up with the statements collected
+// from the current source. The coverage of functions in these files can't be trusted.
+func StaleFiles(packageToFunctions map[string][]profile.FunctionCoverage) []StaleFile {
+	fileFunctions := make(map[string][]functions.Function)
+	fileProfiles := make(map[string]*cover.Profile)
+
+	for _, fcs := range packageToFunctions {
+		for _, fc := range fcs {
+			if fc.Profile == nil || fc.Function.SrcPath == "" {
+				continue
+			}
+
+			path := fc.Function.SrcPath
+			fileFunctions[path] = append(fileFunctions[path], fc.Function)
+			fileProfiles[path] = fc.Profile
+		}
+	}
+
+	out := make([]StaleFile, 0)
+
+	for path, funcs := range fileFunctions {
+		blocks := profile.MisalignedBlocks(funcs, fileProfiles[path].Blocks)
+		if len(blocks) > 0 {
+			out = append(out, StaleFile{Path: path, Blocks: blocks})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+
+	return out
+}
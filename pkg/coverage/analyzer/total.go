@@ -0,0 +1,80 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"sort"
+)
+
+// Total is the coverage of a set of packages, computed from the sum of their statement counts rather than from their
+// percentages
+type Total struct {
+	// Module is the path of the module of the packages, empty for the total of every package
+	Module          string
+	Packages        int
+	StatementCount  int64
+	ExecutedCount   int64
+	CoveragePercent float64
+}
+
+// Total returns the coverage of every package together
+func (p *PackageCoverages) Total() Total {
+	t := Total{}
+
+	for _, cov := range p.coverages {
+		t.add(cov)
+	}
+
+	t.CoveragePercent = CoveragePercent(t.ExecutedCount, t.StatementCount)
+
+	return t
+}
+
+// ModuleTotals returns the coverage of the packages of each module, sorted by module path. Packages outside of a
+// module are left out.
+func (p *PackageCoverages) ModuleTotals() []Total {
+	modules := make(map[string]*Total)
+
+	for _, cov := range p.coverages {
+		if cov.Module == "" {
+			continue
+		}
+
+		t, ok := modules[cov.Module]
+		if !ok {
+			t = &Total{Module: cov.Module}
+			modules[cov.Module] = t
+		}
+
+		t.add(cov)
+	}
+
+	out := make([]Total, 0, len(modules))
+
+	for _, t := range modules {
+		t.CoveragePercent = CoveragePercent(t.ExecutedCount, t.StatementCount)
+		out = append(out, *t)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Module < out[j].Module })
+
+	return out
+}
+
+func (t *Total) add(cov coverage) {
+	t.Packages++
+	t.StatementCount += cov.StatementCount
+	t.ExecutedCount += cov.ExecutedCount
+}
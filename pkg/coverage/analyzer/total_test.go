@@ -0,0 +1,59 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	. "github.com/onsi/gomega"
+)
+
+func Test_PackageCoverages_Total(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pc := NewPackageCoverages(map[string][]profile.FunctionCoverage{
+		"foo/a": {
+			{CoveredCount: 1, StatementCount: 1, Module: "foo"},
+		},
+		"foo/b": {
+			{CoveredCount: 0, StatementCount: 7, Module: "foo"},
+		},
+		"foo/tools/c": {
+			{CoveredCount: 4, StatementCount: 4, Module: "foo/tools"},
+		},
+		"_/tmp/d": {
+			{CoveredCount: 0, StatementCount: 2},
+		},
+	})
+
+	// the total is computed from statements, averaging the package percentages would give 50%
+	total := pc.Total()
+	g.Expect(total.Packages).To(Equal(4))
+	g.Expect(total.ExecutedCount).To(Equal(int64(5)))
+	g.Expect(total.StatementCount).To(Equal(int64(14)))
+	g.Expect(total.CoveragePercent).To(Equal(35.71))
+
+	modules := pc.ModuleTotals()
+	g.Expect(modules).To(HaveLen(2))
+	g.Expect(modules[0].Module).To(Equal("foo"))
+	g.Expect(modules[0].Packages).To(Equal(2))
+	g.Expect(modules[0].CoveragePercent).To(Equal(12.5))
+	g.Expect(modules[1].Module).To(Equal("foo/tools"))
+	g.Expect(modules[1].CoveragePercent).To(Equal(float64(100)))
+
+	empty := NewPackageCoverages(map[string][]profile.FunctionCoverage{}).Total()
+	g.Expect(empty.CoveragePercent).To(Equal(float64(100)))
+}
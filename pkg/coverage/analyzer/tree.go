@@ -0,0 +1,89 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Tree is the coverage of a directory of packages, the sum of the statements of every package at or below Path. The
+// root of a tree has an empty Path.
+type Tree struct {
+	Path            string
+	StatementCount  int64
+	ExecutedCount   int64
+	CoveragePercent float64
+	// Package is true when Path is a package rather than only a directory holding packages
+	Package  bool
+	Children []*Tree
+}
+
+// NewTree rolls the statement counts of the packages up into every directory prefix of their import paths. Children
+// are sorted by path.
+func NewTree(pc *PackageCoverages) *Tree {
+	root := &Tree{}
+	nodes := map[string]*Tree{"": root}
+
+	for pkg, cov := range pc.coverages {
+		root.StatementCount += cov.StatementCount
+		root.ExecutedCount += cov.ExecutedCount
+
+		parent := root
+		elems := strings.Split(pkg, "/")
+
+		for i := range elems {
+			p := strings.Join(elems[:i+1], "/")
+
+			node, ok := nodes[p]
+			if !ok {
+				node = &Tree{Path: p}
+				nodes[p] = node
+				parent.Children = append(parent.Children, node)
+			}
+
+			node.StatementCount += cov.StatementCount
+			node.ExecutedCount += cov.ExecutedCount
+			parent = node
+		}
+
+		parent.Package = true
+	}
+
+	for _, node := range nodes {
+		node.CoveragePercent = CoveragePercent(node.ExecutedCount, node.StatementCount)
+		sort.Slice(node.Children, func(i, j int) bool { return node.Children[i].Path < node.Children[j].Path })
+	}
+
+	return root
+}
+
+// Find returns the tree for the directory p, which may end in /... Both ./... and ... name the tree itself.
+func (t *Tree) Find(p string) (*Tree, bool) {
+	p = path.Clean(strings.TrimSuffix(strings.TrimSuffix(p, "..."), "/"))
+
+	if t.Path == p || p == "." {
+		return t, true
+	}
+
+	for _, c := range t.Children {
+		if c.Path == p || strings.HasPrefix(p, c.Path+"/") {
+			return c.Find(p)
+		}
+	}
+
+	return nil, false
+}
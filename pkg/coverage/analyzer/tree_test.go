@@ -0,0 +1,76 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	. "github.com/onsi/gomega"
+)
+
+func Test_NewTree(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pc := NewPackageCoverages(map[string][]profile.FunctionCoverage{
+		"foo/internal/billing": {
+			{CoveredCount: 1, StatementCount: 4},
+		},
+		"foo/internal/billing/invoice": {
+			{CoveredCount: 8, StatementCount: 8},
+		},
+		"foo/internal/auth": {
+			{CoveredCount: 0, StatementCount: 8},
+		},
+		"foo/cmd": {
+			{CoveredCount: 3, StatementCount: 4},
+		},
+	})
+
+	root := NewTree(pc)
+	g.Expect(root.StatementCount).To(Equal(int64(24)))
+	g.Expect(root.ExecutedCount).To(Equal(int64(12)))
+	g.Expect(root.Children).To(HaveLen(1))
+
+	foo := root.Children[0]
+	g.Expect(foo.Path).To(Equal("foo"))
+	g.Expect(foo.Package).To(BeFalse())
+	g.Expect(foo.Children[0].Path).To(Equal("foo/cmd"))
+	g.Expect(foo.Children[1].Path).To(Equal("foo/internal"))
+
+	// statement counts are summed rather than the percentages averaged
+	billing, ok := root.Find("foo/internal/billing/...")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(billing.Package).To(BeTrue())
+	g.Expect(billing.StatementCount).To(Equal(int64(12)))
+	g.Expect(billing.ExecutedCount).To(Equal(int64(9)))
+	g.Expect(billing.CoveragePercent).To(Equal(float64(75)))
+
+	internal, ok := root.Find("foo/internal")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(internal.CoveragePercent).To(Equal(float64(45)))
+
+	_, ok = root.Find("foo/internal/bill")
+	g.Expect(ok).To(BeFalse())
+
+	_, ok = root.Find("bar")
+	g.Expect(ok).To(BeFalse())
+
+	for _, p := range []string{"./...", "...", "."} {
+		all, ok := root.Find(p)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(all).To(BeIdenticalTo(root))
+	}
+}
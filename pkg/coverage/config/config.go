@@ -15,19 +15,24 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
 )
 
 const (
-	defaultConfigPath = ".gocheckcov-config.yml"
+	DefaultConfigPath = ".gocheckcov-config.yml"
 )
 
 func GetConfigFile(configPath string) ([]byte, error) {
 	var cfContent []byte
 
 	if configPath == "" {
-		configPath = defaultConfigPath
+		configPath = DefaultConfigPath
 	}
 
 	_, err := os.Stat(configPath)
@@ -36,7 +41,7 @@ func GetConfigFile(configPath string) ([]byte, error) {
 			return nil, err
 		}
 
-		if configPath != defaultConfigPath {
+		if configPath != DefaultConfigPath {
 			return nil, err
 		}
 	} else {
@@ -50,21 +55,223 @@ func GetConfigFile(configPath string) ([]byte, error) {
 }
 
 type ConfigFile struct {
-	MinCoveragePercentage float64         `yaml:"min_coverage_percentage"`
-	Packages              []ConfigPackage `yaml:"packages"`
+	MinCoveragePercentage float64 `yaml:"min_coverage_percentage"`
+	// TotalMinCoveragePercentage is the minimum coverage of the statements of every package together
+	TotalMinCoveragePercentage float64 `yaml:"total_min_coverage_percentage,omitempty"`
+	// MinBranchCoveragePercentage is the minimum branch coverage of packages whose rule does not set one
+	MinBranchCoveragePercentage float64 `yaml:"min_branch_coverage_percentage,omitempty"`
+	// MaxCRAPScore fails functions whose CRAP score, which combines complexity and coverage, is above it
+	MaxCRAPScore float64 `yaml:"max_crap_score,omitempty"`
+	// FailOnUntestedPackages fails packages which have no test files regardless of their coverage
+	FailOnUntestedPackages bool             `yaml:"fail_on_untested_packages,omitempty"`
+	Packages               []ConfigPackage  `yaml:"packages"`
+	Functions              []ConfigFunction `yaml:"functions,omitempty"`
+	// Trees hold the packages under directories to a minimum coverage of all their statements together
+	Trees []ConfigTree `yaml:"trees,omitempty"`
+	// TestFlags are passed to go test when gocheckcov runs the tests itself
+	TestFlags []string `yaml:"test_flags,omitempty"`
+	// CoverPkgAll passes every project package to go test as -coverpkg when gocheckcov runs the tests itself
+	CoverPkgAll bool `yaml:"coverpkg_all,omitempty"`
 }
 
+// GetPackage returns the configuration for pkg from the most specific package rule which matches it. The returned
+// package is named pkg.
 func (c ConfigFile) GetPackage(pkg string) (ConfigPackage, bool) {
+	p, ok := c.MatchPackage(pkg)
+	if !ok {
+		return ConfigPackage{}, false
+	}
+
+	p.Name = pkg
+
+	return p, true
+}
+
+// MatchPackage returns the most specific package rule which matches pkg. An exact name always wins, followed by
+// patterns using "..." with the longest literal text, followed by regexes. Ties are won by the rule listed first.
+func (c ConfigFile) MatchPackage(pkg string) (ConfigPackage, bool) {
+	i, ok := c.matchPackageIndex(pkg)
+	if !ok {
+		return ConfigPackage{}, false
+	}
+
+	return c.Packages[i], true
+}
+
+// matchPackageIndex returns the index of the package rule MatchPackage returns for pkg
+func (c ConfigFile) matchPackageIndex(pkg string) (int, bool) {
+	var (
+		best      int
+		bestScore packageMatchScore
+		found     bool
+	)
+
+	for i, p := range c.Packages {
+		score, ok := matchPackageName(p.Name, pkg)
+		if !ok {
+			continue
+		}
+
+		if !found || score.moreSpecificThan(bestScore) {
+			best = i
+			bestScore = score
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// Validate returns an error if any package or function rule is not a valid pattern
+func (c ConfigFile) Validate() error {
+	names := make([]string, 0, len(c.Packages)+2*len(c.Functions))
+
 	for _, p := range c.Packages {
-		if p.Name == pkg {
-			return p, true
+		names = append(names, p.Name)
+	}
+
+	for _, f := range c.Functions {
+		names = append(names, f.Package, f.Name)
+	}
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, regexpPrefix) {
+			continue
+		}
+
+		if _, err := regexp.Compile(strings.TrimPrefix(name, regexpPrefix)); err != nil {
+			return fmt.Errorf("invalid regexp %v %v", name, err)
+		}
+	}
+
+	return ValidateTestFlags(c.TestFlags)
+}
+
+// ValidateTestFlags returns an error if the go test flags set the coverage profile, which gocheckcov sets itself
+func ValidateTestFlags(flags []string) error {
+	if HasTestFlag(flags, "coverprofile") {
+		return fmt.Errorf("test flag -coverprofile is not allowed, the coverage profile is set by gocheckcov")
+	}
+
+	return nil
+}
+
+// HasTestFlag reports whether the go test flags set the flag name, given either as -name or --name and with or
+// without a value after "="
+func HasTestFlag(flags []string, name string) bool {
+	for _, flag := range flags {
+		if !strings.HasPrefix(flag, "-") {
+			continue
+		}
+
+		if strings.SplitN(strings.TrimLeft(flag, "-"), "=", 2)[0] == name {
+			return true
 		}
 	}
 
-	return ConfigPackage{}, false
+	return false
 }
 
 type ConfigPackage struct {
-	Name                  string  `yaml:"name"`
+	Name                        string  `yaml:"name"`
+	MinCoveragePercentage       float64 `yaml:"min_coverage_percentage"`
+	MinBranchCoveragePercentage float64 `yaml:"min_branch_coverage_percentage,omitempty"`
+}
+
+// ConfigTree is a minimum coverage for the statements of every package at or below Path, which may end in /...
+type ConfigTree struct {
+	Path                  string  `yaml:"path"`
 	MinCoveragePercentage float64 `yaml:"min_coverage_percentage"`
 }
+
+// ThresholdChange is a raised minimum of the package rule at Index of the config file
+type ThresholdChange struct {
+	Index int
+	Name  string
+	Old   float64
+	New   float64
+}
+
+// Ratchet raises the minimum coverage of each package rule to the current coverage of the packages it applies to when
+// that is higher. A rule applies to the packages it is the most specific match for, so a pattern rule is raised to the
+// lowest coverage of its packages. Minimums are never lowered and rules without a package with a current coverage are
+// left untouched.
+func (c *ConfigFile) Ratchet(coverages map[string]float64) []ThresholdChange {
+	lowest := make(map[int]float64)
+
+	for pkg, cov := range coverages {
+		i, ok := c.matchPackageIndex(pkg)
+		if !ok {
+			continue
+		}
+
+		if low, ok := lowest[i]; !ok || cov < low {
+			lowest[i] = cov
+		}
+	}
+
+	changes := make([]ThresholdChange, 0)
+
+	for i := range c.Packages {
+		p := &c.Packages[i]
+
+		cov, ok := lowest[i]
+		if !ok || cov <= p.MinCoveragePercentage {
+			continue
+		}
+
+		changes = append(changes, ThresholdChange{Index: i, Name: p.Name, Old: p.MinCoveragePercentage, New: cov})
+		p.MinCoveragePercentage = cov
+	}
+
+	return changes
+}
+
+// SetPackageMinimums returns the content of a config file with the minimum coverage of each changed package rule set
+// to its new value. Keys gocheckcov does not know and the order of keys are kept, comments are not.
+func SetPackageMinimums(content []byte, changes []ThresholdChange) ([]byte, error) {
+	doc := yaml.MapSlice{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("could not unmarshal yaml for config file %v", err)
+	}
+
+	for i, item := range doc {
+		if item.Key != "packages" {
+			continue
+		}
+
+		pkgs, ok := item.Value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("packages of config file is not a list")
+		}
+
+		for _, c := range changes {
+			if c.Index >= len(pkgs) {
+				return nil, fmt.Errorf("config file has no package rule %v", c.Name)
+			}
+
+			rule, ok := pkgs[c.Index].(yaml.MapSlice)
+			if !ok {
+				return nil, fmt.Errorf("package rule %v of config file is not a map", c.Name)
+			}
+
+			pkgs[c.Index] = setKey(rule, "min_coverage_percentage", c.New)
+		}
+
+		doc[i].Value = pkgs
+	}
+
+	return yaml.Marshal(doc)
+}
+
+// setKey sets the value of key in m, adding the key at the end when m does not have it
+func setKey(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i := range m {
+		if m[i].Key == key {
+			m[i].Value = value
+			return m
+		}
+	}
+
+	return append(m, yaml.MapItem{Key: key, Value: value})
+}
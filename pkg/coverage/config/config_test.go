@@ -122,3 +122,283 @@ func Test_ConfigFile_GetPackage(t *testing.T) {
 	g.Expect(ok).To(BeTrue())
 	g.Expect(pkg).To(Equal(pkgs[0]))
 }
+
+func Test_ConfigFile_Ratchet(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := ConfigFile{
+		MinCoveragePercentage: 10,
+		Packages: []ConfigPackage{
+			{Name: "foo/raised", MinCoveragePercentage: 20},
+			{Name: "foo/lower", MinCoveragePercentage: 50},
+			{Name: "foo/unknown", MinCoveragePercentage: 30},
+			{Name: "foo/equal", MinCoveragePercentage: 40},
+		},
+	}
+
+	changes := c.Ratchet(map[string]float64{
+		"foo/raised": 25.5,
+		"foo/lower":  45,
+		"foo/equal":  40,
+		"foo/new":    90,
+	})
+
+	g.Expect(changes).To(Equal([]ThresholdChange{{Index: 0, Name: "foo/raised", Old: 20, New: 25.5}}))
+	g.Expect(c).To(Equal(ConfigFile{
+		MinCoveragePercentage: 10,
+		Packages: []ConfigPackage{
+			{Name: "foo/raised", MinCoveragePercentage: 25.5},
+			{Name: "foo/lower", MinCoveragePercentage: 50},
+			{Name: "foo/unknown", MinCoveragePercentage: 30},
+			{Name: "foo/equal", MinCoveragePercentage: 40},
+		},
+	}))
+}
+
+func Test_ConfigFile_Ratchet_Patterns(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := ConfigFile{
+		Packages: []ConfigPackage{
+			{Name: "foo/...", MinCoveragePercentage: 20},
+			{Name: "foo/bar", MinCoveragePercentage: 10},
+			{Name: "regexp:^baz/", MinCoveragePercentage: 80},
+		},
+	}
+
+	changes := c.Ratchet(map[string]float64{
+		"foo/a":   60,
+		"foo/b":   45.5,
+		"foo/bar": 90,
+		"baz/a":   70,
+	})
+
+	g.Expect(changes).To(Equal([]ThresholdChange{
+		{Index: 0, Name: "foo/...", Old: 20, New: 45.5},
+		{Index: 1, Name: "foo/bar", Old: 10, New: 90},
+	}))
+	g.Expect(c.Packages[2].MinCoveragePercentage).To(Equal(float64(80)))
+}
+
+func Test_SetPackageMinimums(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	content := []byte(`# minimums for the project
+min_coverage_percentage: 10
+packages:
+- name: foo/...
+  min_coverage_percentage: 20
+  owner: team-a
+- name: foo/bar
+future_key: true
+`)
+
+	out, err := SetPackageMinimums(content, []ThresholdChange{
+		{Index: 0, Name: "foo/...", Old: 20, New: 45.5},
+		{Index: 1, Name: "foo/bar", Old: 0, New: 90},
+	})
+	g.Expect(err).To(BeNil())
+	g.Expect(string(out)).To(Equal(`min_coverage_percentage: 10
+packages:
+- name: foo/...
+  min_coverage_percentage: 45.5
+  owner: team-a
+- name: foo/bar
+  min_coverage_percentage: 90
+future_key: true
+`))
+
+	_, err = SetPackageMinimums(content, []ThresholdChange{{Index: 2, Name: "foo/baz"}})
+	g.Expect(err).ToNot(BeNil())
+}
+
+func Test_ConfigFile_MatchPackage(t *testing.T) {
+	c := ConfigFile{
+		Packages: []ConfigPackage{
+			{Name: "regexp:^github.com/acme/.*/internal$", MinCoveragePercentage: 1},
+			{Name: "github.com/acme/svc/...", MinCoveragePercentage: 2},
+			{Name: "github.com/acme/svc/internal/...", MinCoveragePercentage: 3},
+			{Name: "github.com/acme/svc/internal/db", MinCoveragePercentage: 4},
+			{Name: "github.com/acme/.../cmd", MinCoveragePercentage: 5},
+			{Name: "regexp:^github.com/other/", MinCoveragePercentage: 6},
+			{Name: "regexp:^github.com/other/x", MinCoveragePercentage: 7},
+		},
+	}
+
+	type testcase struct {
+		pkg          string
+		expectedRule string
+		notFound     bool
+	}
+
+	testCases := map[string]testcase{
+		"exact name wins over patterns": {
+			pkg:          "github.com/acme/svc/internal/db",
+			expectedRule: "github.com/acme/svc/internal/db",
+		},
+		"longest wildcard pattern wins": {
+			pkg:          "github.com/acme/svc/internal/cache",
+			expectedRule: "github.com/acme/svc/internal/...",
+		},
+		"trailing wildcard matches the package itself": {
+			pkg:          "github.com/acme/svc/internal",
+			expectedRule: "github.com/acme/svc/internal/...",
+		},
+		"wildcard in the middle": {
+			pkg:          "github.com/acme/tool/cmd",
+			expectedRule: "github.com/acme/.../cmd",
+		},
+		"wildcard patterns win over regexps": {
+			pkg:          "github.com/acme/svc/api",
+			expectedRule: "github.com/acme/svc/...",
+		},
+		"first regexp wins a tie of specificity": {
+			pkg:          "github.com/other/x/y",
+			expectedRule: "regexp:^github.com/other/x",
+		},
+		"regexp only": {
+			pkg:          "github.com/acme/tool/internal",
+			expectedRule: "regexp:^github.com/acme/.*/internal$",
+		},
+		"no match": {
+			pkg:      "github.com/acmesvc",
+			notFound: true,
+		},
+	}
+
+	for desc := range testCases {
+		desc := desc
+		t.Run(desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := testCases[desc]
+
+			rule, ok := c.MatchPackage(tc.pkg)
+			if tc.notFound {
+				g.Expect(ok).To(BeFalse())
+				return
+			}
+
+			g.Expect(ok).To(BeTrue())
+			g.Expect(rule.Name).To(Equal(tc.expectedRule))
+
+			pkg, ok := c.GetPackage(tc.pkg)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(pkg.Name).To(Equal(tc.pkg))
+			g.Expect(pkg.MinCoveragePercentage).To(Equal(rule.MinCoveragePercentage))
+		})
+	}
+}
+
+func Test_ConfigFile_Validate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(ConfigFile{Packages: []ConfigPackage{{Name: "regexp:^foo/.*"}, {Name: "foo/..."}}}.Validate()).To(Succeed())
+	g.Expect(ConfigFile{Packages: []ConfigPackage{{Name: "regexp:foo("}}}.Validate()).ToNot(Succeed())
+	g.Expect(ConfigFile{Functions: []ConfigFunction{{Package: "foo", Name: "regexp:^Login"}}}.Validate()).To(Succeed())
+	g.Expect(ConfigFile{Functions: []ConfigFunction{{Package: "foo", Name: "regexp:Login("}}}.Validate()).ToNot(Succeed())
+	g.Expect(ConfigFile{Functions: []ConfigFunction{{Package: "regexp:foo(", Name: "Login"}}}.Validate()).ToNot(Succeed())
+	g.Expect(ConfigFile{TestFlags: []string{"-race", "-tags", "integration"}}.Validate()).To(Succeed())
+	g.Expect(ConfigFile{TestFlags: []string{"-coverprofile=c.out"}}.Validate()).ToNot(Succeed())
+	g.Expect(ConfigFile{TestFlags: []string{"--coverprofile", "c.out"}}.Validate()).ToNot(Succeed())
+}
+
+func Test_HasTestFlag(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(HasTestFlag([]string{"-race", "-coverpkg=./..."}, "coverpkg")).To(BeTrue())
+	g.Expect(HasTestFlag([]string{"--coverpkg", "./..."}, "coverpkg")).To(BeTrue())
+	g.Expect(HasTestFlag([]string{"-run", "coverpkg"}, "coverpkg")).To(BeFalse())
+	g.Expect(HasTestFlag([]string{"-coverpkgs"}, "coverpkg")).To(BeFalse())
+	g.Expect(HasTestFlag(nil, "coverpkg")).To(BeFalse())
+}
+
+func Test_ConfigFile_MatchFunction(t *testing.T) {
+	c := ConfigFile{
+		Functions: []ConfigFunction{
+			{Package: "github.com/acme/...", Name: "regexp:^Charge", MinCoveragePercentage: 1},
+			{Package: "github.com/acme/billing", Name: "regexp:^Charge", MinCoveragePercentage: 2},
+			{Package: "github.com/acme/...", Name: "ChargeCard", MinCoveragePercentage: 3},
+			{Package: "github.com/acme/auth", Name: "Login", MinCoveragePercentage: 4},
+			{Package: "github.com/acme/auth", Name: "regexp:^debug", Exclude: true},
+			{Package: "github.com/acme/server", Name: "Start", MinCoveragePercentage: 5},
+			{Package: "github.com/acme/server", Name: "(*Server).Start", MinCoveragePercentage: 6},
+		},
+	}
+
+	type testcase struct {
+		pkg         string
+		function    string
+		aliases     []string
+		expectedMin float64
+		exclude     bool
+		notFound    bool
+	}
+
+	testCases := map[string]testcase{
+		"exact function name wins over a more specific package": {
+			pkg:         "github.com/acme/billing",
+			function:    "ChargeCard",
+			expectedMin: 3,
+		},
+		"more specific package wins between regexps": {
+			pkg:         "github.com/acme/billing",
+			function:    "ChargeRefund",
+			expectedMin: 2,
+		},
+		"wildcard package": {
+			pkg:         "github.com/acme/shop",
+			function:    "ChargeRefund",
+			expectedMin: 1,
+		},
+		"exact package and function": {
+			pkg:         "github.com/acme/auth",
+			function:    "Login",
+			expectedMin: 4,
+		},
+		"exclusion": {
+			pkg:      "github.com/acme/auth",
+			function: "debugDump",
+			exclude:  true,
+		},
+		"function name does not match": {
+			pkg:      "github.com/acme/auth",
+			function: "Logout",
+			notFound: true,
+		},
+		"qualified method name wins over the method name": {
+			pkg:         "github.com/acme/server",
+			function:    "(*Server).Start",
+			aliases:     []string{"Start"},
+			expectedMin: 6,
+		},
+		"method name matches any receiver": {
+			pkg:         "github.com/acme/server",
+			function:    "(*Client).Start",
+			aliases:     []string{"Start"},
+			expectedMin: 5,
+		},
+		"package does not match": {
+			pkg:      "github.com/other/auth",
+			function: "Login",
+			notFound: true,
+		},
+	}
+
+	for desc := range testCases {
+		desc := desc
+		t.Run(desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := testCases[desc]
+
+			rule, ok := c.MatchFunction(tc.pkg, tc.function, tc.aliases...)
+			if tc.notFound {
+				g.Expect(ok).To(BeFalse())
+				return
+			}
+
+			g.Expect(ok).To(BeTrue())
+			g.Expect(rule.MinCoveragePercentage).To(Equal(tc.expectedMin))
+			g.Expect(rule.Exclude).To(Equal(tc.exclude))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ConfigFunction is a rule for the functions named Name in the packages matching Package. Both may be patterns.
+// Matching functions are either held to their own minimum coverage or excluded from coverage entirely.
+type ConfigFunction struct {
+	Package                     string  `yaml:"package"`
+	Name                        string  `yaml:"name"`
+	MinCoveragePercentage       float64 `yaml:"min_coverage_percentage,omitempty"`
+	MinBranchCoveragePercentage float64 `yaml:"min_branch_coverage_percentage,omitempty"`
+	Exclude                     bool    `yaml:"exclude,omitempty"`
+}
+
+type functionMatchScore struct {
+	name packageMatchScore
+	// alias is true when the rule matched an alias of the function rather than its name
+	alias bool
+	pkg   packageMatchScore
+}
+
+func (s functionMatchScore) moreSpecificThan(other functionMatchScore) bool {
+	if s.name.kind != other.name.kind {
+		return s.name.moreSpecificThan(other.name)
+	}
+
+	if s.alias != other.alias {
+		return !s.alias
+	}
+
+	if s.name != other.name {
+		return s.name.moreSpecificThan(other.name)
+	}
+
+	return s.pkg.moreSpecificThan(other.pkg)
+}
+
+// MatchFunction returns the most specific function rule for the function name in pkg. Rules with an exact function
+// name win over rules with a function regexp, after which the most specific package wins. Ties are won by the rule
+// listed first. Aliases are other names for the function, such as a method name without its receiver type, which
+// rules match less specifically than the name itself.
+func (c ConfigFile) MatchFunction(pkg, name string, aliases ...string) (ConfigFunction, bool) {
+	var (
+		best      ConfigFunction
+		bestScore functionMatchScore
+		found     bool
+	)
+
+	for _, f := range c.Functions {
+		pkgScore, ok := matchPackageName(f.Package, pkg)
+		if !ok {
+			continue
+		}
+
+		nameScore, alias, ok := matchFunctionNames(f.Name, name, aliases)
+		if !ok {
+			continue
+		}
+
+		score := functionMatchScore{name: nameScore, alias: alias, pkg: pkgScore}
+		if !found || score.moreSpecificThan(bestScore) {
+			best = f
+			bestScore = score
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// matchFunctionNames matches the rule name against name and then each alias. It reports whether the match was on an
+// alias.
+func matchFunctionNames(pattern, name string, aliases []string) (packageMatchScore, bool, bool) {
+	if score, ok := matchFunctionName(pattern, name); ok {
+		return score, false, true
+	}
+
+	for _, alias := range aliases {
+		if alias == name {
+			continue
+		}
+
+		if score, ok := matchFunctionName(pattern, alias); ok {
+			return score, true, true
+		}
+	}
+
+	return packageMatchScore{}, false, false
+}
+
+// matchFunctionName reports whether the function rule name matches name. Names prefixed with "regexp:" are regular
+// expressions, any other name must equal the function name.
+func matchFunctionName(pattern, name string) (packageMatchScore, bool) {
+	if strings.HasPrefix(pattern, regexpPrefix) {
+		expr := strings.TrimPrefix(pattern, regexpPrefix)
+
+		re, err := regexp.Compile(expr)
+		if err != nil || !re.MatchString(name) {
+			return packageMatchScore{}, false
+		}
+
+		return packageMatchScore{kind: matchRegexp, literal: len(expr)}, true
+	}
+
+	return packageMatchScore{kind: matchExact, literal: len(pattern)}, pattern == name
+}
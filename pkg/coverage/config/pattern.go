@@ -0,0 +1,90 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	regexpPrefix = "regexp:"
+	wildcard     = "..."
+)
+
+const (
+	matchRegexp = iota
+	matchWildcard
+	matchExact
+)
+
+type packageMatchScore struct {
+	kind    int
+	literal int
+}
+
+func (s packageMatchScore) moreSpecificThan(other packageMatchScore) bool {
+	if s.kind != other.kind {
+		return s.kind > other.kind
+	}
+
+	return s.literal > other.literal
+}
+
+// IsPattern reports whether name is a package pattern rather than a package name
+func IsPattern(name string) bool {
+	return strings.HasPrefix(name, regexpPrefix) || strings.Contains(name, wildcard)
+}
+
+// matchPackageName reports whether the package rule name matches pkg and how specific the match is. Names prefixed
+// with "regexp:" are regular expressions, names containing "..." match any string in its place the same way go
+// package patterns do, and any other name must equal pkg.
+func matchPackageName(name, pkg string) (packageMatchScore, bool) {
+	if strings.HasPrefix(name, regexpPrefix) {
+		expr := strings.TrimPrefix(name, regexpPrefix)
+
+		re, err := regexp.Compile(expr)
+		if err != nil || !re.MatchString(pkg) {
+			return packageMatchScore{}, false
+		}
+
+		return packageMatchScore{kind: matchRegexp, literal: len(expr)}, true
+	}
+
+	if !strings.Contains(name, wildcard) {
+		return packageMatchScore{kind: matchExact, literal: len(name)}, name == pkg
+	}
+
+	if !wildcardRegexp(name).MatchString(pkg) {
+		return packageMatchScore{}, false
+	}
+
+	literal := len(strings.Replace(name, wildcard, "", -1))
+
+	return packageMatchScore{kind: matchWildcard, literal: literal}, true
+}
+
+// wildcardRegexp converts a go package pattern into a regexp. As with the go tool a trailing "/..." also matches
+// the package itself, so foo/... matches foo and foo/bar.
+func wildcardRegexp(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.Replace(expr, regexp.QuoteMeta(wildcard), ".*", -1)
+
+	if strings.HasSuffix(expr, "/.*") {
+		expr = strings.TrimSuffix(expr, "/.*") + "(/.*)?"
+	}
+
+	return regexp.MustCompile("^" + expr + "$")
+}
@@ -0,0 +1,188 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"golang.org/x/tools/cover"
+)
+
+type Coverage struct {
+	StatementCount  int64               `json:"statement_count"`
+	ExecutedCount   int64               `json:"executed_count"`
+	CoveragePercent float64             `json:"coverage_percentage"`
+	Uncovered       []UncoveredFunction `json:"uncovered"`
+}
+
+type UncoveredFunction struct {
+	Package  string `json:"package"`
+	SrcPath  string `json:"src_path"`
+	Function string `json:"function"`
+	Lines    []int  `json:"lines"`
+}
+
+// NewCoverage computes the coverage of the statements which start on the changed lines. Functions without profile
+// data count each of their changed statements as uncovered.
+func NewCoverage(
+	packageToFunctions map[string][]profile.FunctionCoverage,
+	changed map[string][]LineRange,
+) Coverage {
+	changedByPath := make(map[string][]LineRange, len(changed))
+	for path, ranges := range changed {
+		changedByPath[realPath(path)] = ranges
+	}
+
+	cov := Coverage{Uncovered: make([]UncoveredFunction, 0)}
+	resolved := make(map[string]string)
+
+	for _, pkg := range sortedPackages(packageToFunctions) {
+		for _, fc := range packageToFunctions[pkg] {
+			srcPath, ok := resolved[fc.Function.SrcPath]
+			if !ok {
+				srcPath = realPath(fc.Function.SrcPath)
+				resolved[fc.Function.SrcPath] = srcPath
+			}
+
+			ranges, ok := changedByPath[srcPath]
+			if !ok {
+				continue
+			}
+
+			statements, executed, lines := functionDiffCoverage(fc, ranges)
+			cov.StatementCount += statements
+			cov.ExecutedCount += executed
+
+			if len(lines) > 0 {
+				cov.Uncovered = append(cov.Uncovered, UncoveredFunction{
+					Package:  pkg,
+					SrcPath:  fc.Function.SrcPath,
+					Function: fc.Name,
+					Lines:    lines,
+				})
+			}
+		}
+	}
+
+	cov.CoveragePercent = analyzer.CoveragePercent(cov.ExecutedCount, cov.StatementCount)
+
+	return cov
+}
+
+// functionDiffCoverage counts the statements of the function and its closures which start on a changed line. A
+// statement is executed when the profile block holding it was executed, so a changed line does not pull in the rest of
+// its block.
+func functionDiffCoverage(fc profile.FunctionCoverage, ranges []LineRange) (int64, int64, []int) {
+	var statements, executed int64
+
+	uncovered := make(map[int]bool)
+
+	for _, stmt := range functionStatements(nil, fc.Function) {
+		line := int(stmt.StartLine)
+		if !lineChanged(line, ranges) {
+			continue
+		}
+
+		statements++
+
+		if statementExecuted(stmt, fc.Blocks) {
+			executed++
+			continue
+		}
+
+		uncovered[line] = true
+	}
+
+	return statements, executed, sortedLines(uncovered)
+}
+
+// functionStatements appends the statements of the function and its closures, leaving out ignored statements
+func functionStatements(stmts []statements.Statement, f functions.Function) []statements.Statement {
+	stmts = append(stmts, f.Statements...)
+
+	for _, c := range f.Closures {
+		stmts = functionStatements(stmts, c)
+	}
+
+	return stmts
+}
+
+// statementExecuted reports whether the statement starts within an executed block
+func statementExecuted(stmt statements.Statement, blocks []cover.ProfileBlock) bool {
+	line, col := int(stmt.StartLine), int(stmt.StartCol)
+
+	for _, b := range blocks {
+		startsIn := line > b.StartLine || (line == b.StartLine && col >= b.StartCol)
+		endsIn := line < b.EndLine || (line == b.EndLine && col < b.EndCol)
+
+		if startsIn && endsIn {
+			return b.Count > 0
+		}
+	}
+
+	return false
+}
+
+func lineChanged(line int, ranges []LineRange) bool {
+	for _, r := range ranges {
+		if r.Contains(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedLines(lines map[int]bool) []int {
+	out := make([]int, 0, len(lines))
+	for line := range lines {
+		out = append(out, line)
+	}
+
+	sort.Ints(out)
+
+	return out
+}
+
+func realPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return abs
+	}
+
+	return resolved
+}
+
+func sortedPackages(packageToFunctions map[string][]profile.FunctionCoverage) []string {
+	keys := make([]string, 0, len(packageToFunctions))
+
+	for pkg := range packageToFunctions {
+		keys = append(keys, pkg)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
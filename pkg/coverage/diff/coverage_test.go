@@ -0,0 +1,129 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+func Test_NewCoverage(t *testing.T) {
+	packageToFunctions := map[string][]profile.FunctionCoverage{
+		"foo/bar": []profile.FunctionCoverage{
+			{
+				Name: "Meow",
+				Function: functions.Function{
+					Name:    "Meow",
+					SrcPath: "/src/foo/bar/meow.go",
+					Statements: []statements.Statement{
+						{StartLine: 3, StartCol: 2},
+						{StartLine: 4, StartCol: 2},
+						{StartLine: 5, StartCol: 5},
+						{StartLine: 6, StartCol: 3},
+						{StartLine: 8, StartCol: 2},
+						{StartLine: 8, StartCol: 10},
+						{StartLine: 9, StartCol: 2},
+					},
+					Closures: []functions.Function{
+						{
+							Name:       "Meow.func1",
+							Statements: []statements.Statement{{StartLine: 4, StartCol: 12}},
+						},
+					},
+				},
+				Blocks: []cover.ProfileBlock{
+					{StartLine: 3, StartCol: 1, EndLine: 5, EndCol: 3, NumStmt: 3, Count: 1},
+					{StartLine: 5, StartCol: 4, EndLine: 7, EndCol: 2, NumStmt: 2, Count: 0},
+					{StartLine: 8, StartCol: 1, EndLine: 9, EndCol: 20, NumStmt: 3, Count: 0},
+				},
+			},
+			{
+				Name: "Purr",
+				Function: functions.Function{
+					Name:    "Purr",
+					SrcPath: "/src/foo/bar/purr.go",
+					Statements: []statements.Statement{
+						{StartLine: 2, EndLine: 2},
+						{StartLine: 3, EndLine: 3},
+					},
+				},
+			},
+		},
+	}
+
+	type testcase struct {
+		changed  map[string][]LineRange
+		expected Coverage
+	}
+
+	testCases := map[string]testcase{
+		"no changes": {
+			changed:  map[string][]LineRange{},
+			expected: Coverage{CoveragePercent: 100, Uncovered: []UncoveredFunction{}},
+		},
+		"changes in covered and uncovered blocks": {
+			changed: map[string][]LineRange{
+				"/src/foo/bar/meow.go": {{Start: 4, End: 6}},
+			},
+			expected: Coverage{
+				StatementCount:  4,
+				ExecutedCount:   2,
+				CoveragePercent: 50,
+				Uncovered: []UncoveredFunction{
+					{Package: "foo/bar", SrcPath: "/src/foo/bar/meow.go", Function: "Meow", Lines: []int{5, 6}},
+				},
+			},
+		},
+		"changes to part of a block": {
+			changed: map[string][]LineRange{
+				"/src/foo/bar/meow.go": {{Start: 9, End: 9}},
+			},
+			expected: Coverage{
+				StatementCount:  1,
+				CoveragePercent: 0,
+				Uncovered: []UncoveredFunction{
+					{Package: "foo/bar", SrcPath: "/src/foo/bar/meow.go", Function: "Meow", Lines: []int{9}},
+				},
+			},
+		},
+		"changes in a function without profile data": {
+			changed: map[string][]LineRange{
+				"/src/foo/bar/purr.go": {{Start: 3, End: 3}},
+			},
+			expected: Coverage{
+				StatementCount:  1,
+				CoveragePercent: 0,
+				Uncovered: []UncoveredFunction{
+					{Package: "foo/bar", SrcPath: "/src/foo/bar/purr.go", Function: "Purr", Lines: []int{3}},
+				},
+			},
+		},
+	}
+
+	for desc := range testCases {
+		desc := desc
+		t.Run(desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := testCases[desc]
+
+			g.Expect(NewCoverage(packageToFunctions, tc.changed)).To(Equal(tc.expected))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -[0-9]+(?:,[0-9]+)? \+([0-9]+)(?:,([0-9]+))? @@`)
+
+type LineRange struct {
+	Start int
+	End   int
+}
+
+func (r LineRange) Contains(line int) bool {
+	return line >= r.Start && line <= r.End
+}
+
+// ChangedLines maps the absolute path of each file changed between base and the working tree of the git repository
+// containing dir to the line ranges which were added or modified
+func ChangedLines(dir, base string) (map[string][]LineRange, error) {
+	topLevel, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	root := strings.TrimSpace(string(topLevel))
+
+	out, err := git(
+		root,
+		"diff",
+		"--unified=0",
+		"--no-color",
+		"--no-ext-diff",
+		"--src-prefix=a/",
+		"--dst-prefix=b/",
+		base,
+		"--",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := ParseDiff(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+
+	abs := make(map[string][]LineRange, len(changed))
+	for path, ranges := range changed {
+		abs[filepath.Join(root, filepath.FromSlash(path))] = ranges
+	}
+
+	return abs, nil
+}
+
+func git(dir string, args ...string) ([]byte, error) {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+
+	stderr := bytes.NewBuffer(nil)
+	c.Stderr = stderr
+
+	log.Debugf("running git %v in %v", strings.Join(args, " "), dir)
+
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %v failed %v %v", strings.Join(args, " "), err, stderr.String())
+	}
+
+	return out, nil
+}
+
+// ParseDiff reads a unified diff and maps the path of each file in the new version to the line ranges which were added
+// or modified. Deleted files are not included.
+func ParseDiff(r io.Reader) (map[string][]LineRange, error) {
+	changed := make(map[string][]LineRange)
+
+	var current string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			current = ""
+
+			name := strings.TrimPrefix(line, "+++ ")
+			if strings.HasPrefix(name, "b/") {
+				current = strings.TrimPrefix(name, "b/")
+			}
+		case strings.HasPrefix(line, "@@ "):
+			if current == "" {
+				continue
+			}
+
+			lr, ok, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				changed[current] = append(changed[current], lr)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+func parseHunkHeader(line string) (LineRange, bool, error) {
+	match := hunkHeader.FindStringSubmatch(line)
+	if match == nil {
+		return LineRange{}, false, fmt.Errorf("could not parse hunk header %v", line)
+	}
+
+	start, err := strconv.Atoi(match[1])
+	if err != nil {
+		return LineRange{}, false, err
+	}
+
+	count := 1
+
+	if match[2] != "" {
+		count, err = strconv.Atoi(match[2])
+		if err != nil {
+			return LineRange{}, false, err
+		}
+	}
+
+	if count == 0 {
+		// lines were only removed
+		return LineRange{}, false, nil
+	}
+
+	return LineRange{Start: start, End: start + count - 1}, true, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_ParseDiff(t *testing.T) {
+	type testcase struct {
+		diff      string
+		expected  map[string][]LineRange
+		expectErr bool
+	}
+
+	testCases := map[string]testcase{
+		"empty diff": {
+			expected: map[string][]LineRange{},
+		},
+		"modified, added and deleted files": {
+			diff: `diff --git a/pkg/foo.go b/pkg/foo.go
+index 1111111..2222222 100644
+--- a/pkg/foo.go
++++ b/pkg/foo.go
+@@ -3 +3 @@ package foo
+-func a() {}
++func b() {}
+@@ -10,2 +10,0 @@ func c() {
+-	x()
+-	y()
+@@ -20,0 +19,3 @@ func d() {
++	x()
++	y()
++	z()
+diff --git a/pkg/bar.go b/pkg/bar.go
+new file mode 100644
+--- /dev/null
++++ b/pkg/bar.go
+@@ -0,0 +1,2 @@
++package pkg
++
+diff --git a/pkg/baz.go b/pkg/baz.go
+deleted file mode 100644
+--- a/pkg/baz.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package pkg
+-
+`,
+			expected: map[string][]LineRange{
+				"pkg/foo.go": {{Start: 3, End: 3}, {Start: 19, End: 21}},
+				"pkg/bar.go": {{Start: 1, End: 2}},
+			},
+		},
+		"bad hunk header": {
+			diff:      "+++ b/foo.go\n@@ meow @@\n",
+			expectErr: true,
+		},
+	}
+
+	for desc := range testCases {
+		desc := desc
+		t.Run(desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := testCases[desc]
+
+			changed, err := ParseDiff(strings.NewReader(tc.diff))
+			if tc.expectErr {
+				g.Expect(err).ToNot(BeNil())
+			} else {
+				g.Expect(err).To(BeNil())
+				g.Expect(changed).To(Equal(tc.expected))
+			}
+		})
+	}
+}
+
+func Test_ChangedLines(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Errorf("could not create temp dir %v", err)
+		t.FailNow()
+	}
+
+	run := func(args ...string) {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Errorf("git %v failed %v %s", args, err, out)
+			t.FailNow()
+		}
+	}
+
+	srcPath := filepath.Join(dir, "src.go")
+
+	if err := ioutil.WriteFile(srcPath, []byte("package foo\n\nfunc a() {}\n"), 0644); err != nil {
+		t.Errorf("could not write to temp file %v", err)
+		t.FailNow()
+	}
+
+	run("init", "-q")
+	run("add", "-A")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init")
+
+	if err := ioutil.WriteFile(srcPath, []byte("package foo\n\nfunc a() {}\n\nfunc b() {}\n"), 0644); err != nil {
+		t.Errorf("could not write to temp file %v", err)
+		t.FailNow()
+	}
+
+	changed, err := ChangedLines(dir, "HEAD")
+	g.Expect(err).To(BeNil())
+	g.Expect(changed).To(HaveLen(1))
+
+	for path, ranges := range changed {
+		g.Expect(realPath(path)).To(Equal(realPath(srcPath)))
+		g.Expect(ranges).To(Equal([]LineRange{{Start: 4, End: 5}}))
+	}
+
+	_, err = ChangedLines(dir, "not-a-ref")
+	g.Expect(err).ToNot(BeNil())
+}
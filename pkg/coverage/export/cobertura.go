@@ -0,0 +1,202 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+)
+
+const coberturaDocType = `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`
+
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        float64            `xml:"line-rate,attr"`
+	BranchRate      float64            `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      float64            `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity float64          `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string            `xml:"name,attr"`
+	Filename   string            `xml:"filename,attr"`
+	LineRate   float64           `xml:"line-rate,attr"`
+	BranchRate float64           `xml:"branch-rate,attr"`
+	Complexity float64           `xml:"complexity,attr"`
+	Methods    []coberturaMethod `xml:"methods>method"`
+	Lines      []coberturaLine   `xml:"lines>line"`
+}
+
+type coberturaMethod struct {
+	Name       string          `xml:"name,attr"`
+	Signature  string          `xml:"signature,attr"`
+	LineRate   float64         `xml:"line-rate,attr"`
+	BranchRate float64         `xml:"branch-rate,attr"`
+	Complexity float64         `xml:"complexity,attr"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int   `xml:"number,attr"`
+	Hits   int64 `xml:"hits,attr"`
+}
+
+// coberturaCounts holds the covered and valid lines and branches of a package or file
+type coberturaCounts struct {
+	LinesCovered    int
+	LinesValid      int
+	BranchesCovered int
+	BranchesValid   int
+}
+
+func (c *coberturaCounts) add(o coberturaCounts) {
+	c.LinesCovered += o.LinesCovered
+	c.LinesValid += o.LinesValid
+	c.BranchesCovered += o.BranchesCovered
+	c.BranchesValid += o.BranchesValid
+}
+
+// WriteCobertura writes the coverage for each package as a Cobertura XML report. File names are written relative to
+// sourceDir.
+func WriteCobertura(
+	w io.Writer,
+	packageToFunctions map[string][]profile.FunctionCoverage,
+	sourceDir string,
+) error {
+	cov := coberturaCoverage{
+		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+		Sources:   []string{sourceDir},
+	}
+
+	var counts coberturaCounts
+
+	for _, pkg := range sortedPackages(packageToFunctions) {
+		cp, pkgCounts := newCoberturaPackage(pkg, packageToFunctions[pkg], sourceDir)
+		cov.Packages = append(cov.Packages, cp)
+		counts.add(pkgCounts)
+	}
+
+	cov.LinesCovered = counts.LinesCovered
+	cov.LinesValid = counts.LinesValid
+	cov.BranchesCovered = counts.BranchesCovered
+	cov.BranchesValid = counts.BranchesValid
+	cov.LineRate = coverageRate(counts.LinesCovered, counts.LinesValid)
+	cov.BranchRate = coverageRate(counts.BranchesCovered, counts.BranchesValid)
+
+	if _, err := io.WriteString(w, xml.Header+coberturaDocType+"\n"); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(cov); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+func newCoberturaPackage(
+	name string,
+	functions []profile.FunctionCoverage,
+	sourceDir string,
+) (coberturaPackage, coberturaCounts) {
+	cp := coberturaPackage{Name: name}
+
+	var counts coberturaCounts
+
+	for _, file := range filesForFunctions(functions) {
+		class, fileCounts := newCoberturaClass(file, sourceDir)
+		cp.Classes = append(cp.Classes, class)
+		counts.add(fileCounts)
+	}
+
+	cp.LineRate = coverageRate(counts.LinesCovered, counts.LinesValid)
+	cp.BranchRate = coverageRate(counts.BranchesCovered, counts.BranchesValid)
+
+	return cp, counts
+}
+
+func newCoberturaClass(file sourceFile, sourceDir string) (coberturaClass, coberturaCounts) {
+	filename := file.Path
+	if rel, err := filepath.Rel(sourceDir, file.Path); err == nil {
+		filename = rel
+	}
+
+	class := coberturaClass{
+		Name:     filepath.Base(file.Path),
+		Filename: filepath.ToSlash(filename),
+	}
+
+	var (
+		fileLines []lineHit
+		counts    coberturaCounts
+	)
+
+	for _, fc := range file.Functions {
+		lines := functionLineHits(fc)
+		method := coberturaMethod{
+			Name:       fc.Name,
+			LineRate:   coverageRate(coveredLines(lines), len(lines)),
+			BranchRate: coverageRate(int(fc.CoveredBranchCount), int(fc.BranchCount)),
+			Lines:      coberturaLines(lines),
+		}
+
+		class.Methods = append(class.Methods, method)
+		fileLines = append(fileLines, lines...)
+		counts.BranchesCovered += int(fc.CoveredBranchCount)
+		counts.BranchesValid += int(fc.BranchCount)
+	}
+
+	class.Lines = coberturaLines(fileLines)
+	counts.LinesCovered = coveredLines(fileLines)
+	counts.LinesValid = len(fileLines)
+	class.LineRate = coverageRate(counts.LinesCovered, counts.LinesValid)
+	class.BranchRate = coverageRate(counts.BranchesCovered, counts.BranchesValid)
+
+	return class, counts
+}
+
+func coberturaLines(lines []lineHit) []coberturaLine {
+	out := make([]coberturaLine, 0, len(lines))
+
+	for _, l := range lines {
+		out = append(out, coberturaLine{Number: l.Number, Hits: l.Hits})
+	}
+
+	return out
+}
@@ -0,0 +1,106 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+func testPackageToFunctions() map[string][]profile.FunctionCoverage {
+	return map[string][]profile.FunctionCoverage{
+		"foo/bar": []profile.FunctionCoverage{
+			{
+				Name:               "Meow",
+				StatementCount:     3,
+				CoveredCount:       2,
+				BranchCount:        4,
+				CoveredBranchCount: 3,
+				Function:           functions.Function{Name: "Meow", SrcPath: "/src/foo/bar/meow.go", StartLine: 3},
+				Blocks: []cover.ProfileBlock{
+					{StartLine: 3, EndLine: 5, NumStmt: 2, Count: 4},
+					{StartLine: 5, EndLine: 6, NumStmt: 1, Count: 0},
+				},
+			},
+			{
+				Name:           "Purr",
+				StatementCount: 1,
+				BranchCount:    2,
+				Function: functions.Function{
+					Name:       "Purr",
+					SrcPath:    "/src/foo/bar/purr.go",
+					StartLine:  10,
+					Statements: []statements.Statement{{StartLine: 11, EndLine: 11}},
+				},
+			},
+		},
+	}
+}
+
+func Test_functionLineHits(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fcs := testPackageToFunctions()["foo/bar"]
+
+	g.Expect(functionLineHits(fcs[0])).To(Equal([]lineHit{
+		{Number: 3, Hits: 4},
+		{Number: 4, Hits: 4},
+		{Number: 5, Hits: 4},
+		{Number: 6, Hits: 0},
+	}))
+	g.Expect(functionLineHits(fcs[1])).To(Equal([]lineHit{{Number: 11, Hits: 0}}))
+}
+
+func Test_WriteCobertura(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	before := time.Now().UnixNano() / int64(time.Millisecond)
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteCobertura(buf, testPackageToFunctions(), "/src")).To(Succeed())
+	g.Expect(buf.String()).To(ContainSubstring(coberturaDocType))
+
+	actual := coberturaCoverage{}
+	g.Expect(xml.Unmarshal(buf.Bytes(), &actual)).To(Succeed())
+	g.Expect(actual.Timestamp).To(BeNumerically(">=", before))
+	g.Expect(actual.LinesValid).To(Equal(5))
+	g.Expect(actual.LinesCovered).To(Equal(3))
+	g.Expect(actual.BranchesValid).To(Equal(6))
+	g.Expect(actual.BranchesCovered).To(Equal(3))
+	g.Expect(actual.BranchRate).To(Equal(0.5))
+	g.Expect(actual.Packages).To(HaveLen(1))
+
+	pkg := actual.Packages[0]
+	g.Expect(pkg.Name).To(Equal("foo/bar"))
+	g.Expect(pkg.BranchRate).To(Equal(0.5))
+	g.Expect(pkg.Classes).To(HaveLen(2))
+	g.Expect(pkg.Classes[0].Filename).To(Equal("foo/bar/meow.go"))
+	g.Expect(pkg.Classes[0].LineRate).To(Equal(0.75))
+	g.Expect(pkg.Classes[0].Methods).To(HaveLen(1))
+	g.Expect(pkg.Classes[0].Methods[0].Name).To(Equal("Meow"))
+	g.Expect(pkg.Classes[0].Methods[0].BranchRate).To(Equal(0.75))
+	g.Expect(pkg.Classes[0].Methods[0].Lines).To(HaveLen(4))
+	g.Expect(pkg.Classes[1].Filename).To(Equal("foo/bar/purr.go"))
+	g.Expect(pkg.Classes[1].LineRate).To(Equal(float64(0)))
+	g.Expect(pkg.Classes[1].BranchRate).To(Equal(float64(0)))
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"sort"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+)
+
+type lineHit struct {
+	Number int
+	Hits   int64
+}
+
+// functionLineHits returns the hit count for each line of a function that is covered by a profile block. Functions
+// without profile data report a zero hit count for the first line of each statement.
+func functionLineHits(fc profile.FunctionCoverage) []lineHit {
+	hits := make(map[int]int64)
+
+	if len(fc.Blocks) == 0 {
+		for _, stmt := range fc.Function.Statements {
+			hits[int(stmt.StartLine)] = 0
+		}
+	}
+
+	for _, block := range fc.Blocks {
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			if count, ok := hits[line]; !ok || int64(block.Count) > count {
+				hits[line] = int64(block.Count)
+			}
+		}
+	}
+
+	out := make([]lineHit, 0, len(hits))
+	for line, count := range hits {
+		out = append(out, lineHit{Number: line, Hits: count})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+
+	return out
+}
+
+func coveredLines(lines []lineHit) int {
+	covered := 0
+
+	for _, l := range lines {
+		if l.Hits > 0 {
+			covered++
+		}
+	}
+
+	return covered
+}
+
+// coverageRate returns the fraction of valid lines or branches which are covered, which is 1 when there are none
+func coverageRate(covered, valid int) float64 {
+	if valid == 0 {
+		return 1
+	}
+
+	return float64(covered) / float64(valid)
+}
+
+type sourceFile struct {
+	Path      string
+	Functions []profile.FunctionCoverage
+}
+
+// filesForFunctions groups functions by their source file. Files are sorted by path and functions keep their order.
+func filesForFunctions(functions []profile.FunctionCoverage) []sourceFile {
+	index := make(map[string]int)
+	out := make([]sourceFile, 0)
+
+	for _, fc := range functions {
+		path := fc.Function.SrcPath
+
+		i, ok := index[path]
+		if !ok {
+			i = len(out)
+			index[path] = i
+			out = append(out, sourceFile{Path: path})
+		}
+
+		out[i].Functions = append(out[i].Functions, fc)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+
+	return out
+}
+
+func sortedPackages(packageToFunctions map[string][]profile.FunctionCoverage) []string {
+	keys := make([]string, 0, len(packageToFunctions))
+
+	for pkg := range packageToFunctions {
+		keys = append(keys, pkg)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,134 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+)
+
+// WriteLCOV writes the coverage for each package as an LCOV tracefile with one record per source file. File names are
+// written relative to sourceDir.
+func WriteLCOV(
+	w io.Writer,
+	packageToFunctions map[string][]profile.FunctionCoverage,
+	sourceDir string,
+) error {
+	all := make([]profile.FunctionCoverage, 0)
+	for _, pkg := range sortedPackages(packageToFunctions) {
+		all = append(all, packageToFunctions[pkg]...)
+	}
+
+	out := bufio.NewWriter(w)
+
+	for _, file := range filesForFunctions(all) {
+		if err := writeLCOVRecord(out, file, sourceDir); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
+
+func writeLCOVRecord(w io.Writer, file sourceFile, sourceDir string) error {
+	filename := file.Path
+	if rel, err := filepath.Rel(sourceDir, file.Path); err == nil {
+		filename = rel
+	}
+
+	lines := []string{"TN:", fmt.Sprintf("SF:%v", filepath.ToSlash(filename))}
+	fileHits := make(map[int]int64)
+
+	var functionsHit int
+
+	for _, fc := range file.Functions {
+		lines = append(lines, fmt.Sprintf("FN:%v,%v", fc.Function.StartLine, fc.Name))
+	}
+
+	for _, fc := range file.Functions {
+		count := functionExecutionCount(fc)
+		if count > 0 {
+			functionsHit++
+		}
+
+		lines = append(lines, fmt.Sprintf("FNDA:%v,%v", count, fc.Name))
+
+		for _, l := range functionLineHits(fc) {
+			if hits, ok := fileHits[l.Number]; !ok || l.Hits > hits {
+				fileHits[l.Number] = l.Hits
+			}
+		}
+	}
+
+	lines = append(
+		lines,
+		fmt.Sprintf("FNF:%v", len(file.Functions)),
+		fmt.Sprintf("FNH:%v", functionsHit),
+	)
+
+	lineNumbers := make([]int, 0, len(fileHits))
+	for n := range fileHits {
+		lineNumbers = append(lineNumbers, n)
+	}
+
+	sort.Ints(lineNumbers)
+
+	var linesHit int
+
+	for _, n := range lineNumbers {
+		if fileHits[n] > 0 {
+			linesHit++
+		}
+
+		lines = append(lines, fmt.Sprintf("DA:%v,%v", n, fileHits[n]))
+	}
+
+	lines = append(
+		lines,
+		fmt.Sprintf("LF:%v", len(lineNumbers)),
+		fmt.Sprintf("LH:%v", linesHit),
+		"end_of_record",
+	)
+
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(w, l); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// functionExecutionCount is the count of the first block of the function, which is executed on every call
+func functionExecutionCount(fc profile.FunctionCoverage) int64 {
+	if len(fc.Blocks) == 0 {
+		return 0
+	}
+
+	first := fc.Blocks[0]
+
+	for _, b := range fc.Blocks[1:] {
+		if b.StartLine < first.StartLine || (b.StartLine == first.StartLine && b.StartCol < first.StartCol) {
+			first = b
+		}
+	}
+
+	return int64(first.Count)
+}
@@ -0,0 +1,55 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_WriteLCOV(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteLCOV(buf, testPackageToFunctions(), "/src")).To(Succeed())
+
+	expected := `TN:
+SF:foo/bar/meow.go
+FN:3,Meow
+FNDA:4,Meow
+FNF:1
+FNH:1
+DA:3,4
+DA:4,4
+DA:5,4
+DA:6,0
+LF:4
+LH:3
+end_of_record
+TN:
+SF:foo/bar/purr.go
+FN:10,Purr
+FNDA:0,Purr
+FNF:1
+FNH:0
+DA:11,0
+LF:1
+LH:0
+end_of_record
+`
+	g.Expect(buf.String()).To(Equal(expected))
+}
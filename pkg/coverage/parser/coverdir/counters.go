@@ -0,0 +1,144 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverdir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+var counterMagic = []byte{0x00, 0x63, 0x77, 0x6d}
+
+const (
+	counterFileVersion = 1
+	// counterFileHeaderSize is the size of the header of a counter data file
+	counterFileHeaderSize = 32
+	// counterFileFooterSize is the size of the footer written after each segment of a counter data file
+	counterFileFooterSize = 16
+)
+
+// counter encodings of the counter data file header
+const (
+	flavorRaw     = 1
+	flavorULEB128 = 2
+)
+
+type counterFile struct {
+	MetaHash  [16]byte
+	Functions []functionCounters
+}
+
+// functionCounters are the counters of the function FuncIdx in package PkgIdx of the matching meta-data file
+type functionCounters struct {
+	PkgIdx   uint32
+	FuncIdx  uint32
+	Counters []uint32
+}
+
+// parseCounterFile decodes a covcounters file written by a binary built with -cover
+func parseCounterFile(b []byte) (counterFile, error) {
+	r := newByteReader(b)
+
+	if !bytes.Equal(r.next(4), counterMagic) {
+		return counterFile{}, fmt.Errorf("not a coverage counter data file")
+	}
+
+	if version := r.uint32(binary.LittleEndian); version > counterFileVersion {
+		return counterFile{}, fmt.Errorf("unsupported counter data file version %v", version)
+	}
+
+	cf := counterFile{}
+	copy(cf.MetaHash[:], r.next(16))
+	flavor := r.uint8()
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if r.uint8() != 0 {
+		order = binary.BigEndian
+	}
+
+	if flavor != flavorRaw && flavor != flavorULEB128 {
+		return counterFile{}, fmt.Errorf("unsupported counter encoding %v", flavor)
+	}
+
+	r.seek(len(b) - counterFileFooterSize)
+
+	if !bytes.Equal(r.next(4), counterMagic) {
+		return counterFile{}, fmt.Errorf("malformed counter data file footer")
+	}
+
+	r.next(4)
+	segments := r.uint32(binary.LittleEndian)
+
+	r.seek(counterFileHeaderSize)
+
+	readValue := func() uint32 {
+		if flavor == flavorULEB128 {
+			return uint32(r.uleb128())
+		}
+
+		return r.uint32(order)
+	}
+
+	for s := uint32(0); s < segments && r.err == nil; s++ {
+		cf.Functions = append(cf.Functions, readSegment(r, readValue)...)
+		r.next(counterFileFooterSize)
+	}
+
+	return cf, r.err
+}
+
+// readSegment reads the counters of every function in a segment. The string table and arguments of the segment
+// describe the process which wrote it and are skipped.
+func readSegment(r *byteReader, readValue func() uint32) []functionCounters {
+	segmentStart := r.off
+	entries := r.uint64()
+	strTabLen := r.uint32(binary.LittleEndian)
+	argsLen := r.uint32(binary.LittleEndian)
+
+	r.seek(r.off + int(strTabLen) + int(argsLen))
+
+	if pad := (r.off - segmentStart) % 4; pad != 0 {
+		r.next(4 - pad)
+	}
+
+	if r.err != nil || entries > uint64(len(r.b)) {
+		return nil
+	}
+
+	out := make([]functionCounters, 0, entries)
+
+	for i := uint64(0); i < entries && r.err == nil; i++ {
+		numCounters := readValue()
+		fc := functionCounters{
+			PkgIdx:  readValue(),
+			FuncIdx: readValue(),
+		}
+
+		if uint64(numCounters) > uint64(len(r.b)) {
+			r.err = fmt.Errorf("malformed counter count %v", numCounters)
+			return nil
+		}
+
+		fc.Counters = make([]uint32, 0, numCounters)
+		for j := uint32(0); j < numCounters; j++ {
+			fc.Counters = append(fc.Counters, readValue())
+		}
+
+		out = append(out, fc)
+	}
+
+	return out
+}
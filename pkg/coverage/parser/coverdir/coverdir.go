@@ -0,0 +1,163 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package coverdir decodes the binary coverage data that binaries built with -cover write to GOCOVERDIR
+package coverdir
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/tools/cover"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+)
+
+const (
+	metaFilePrefix    = "covmeta."
+	counterFilePrefix = "covcounters."
+)
+
+// ReadProfiles decodes the meta-data and counter files in dir and returns them as text coverage profiles. Counters
+// from several runs of the same binary are merged the same way profiles are.
+func ReadProfiles(dir string) ([]*cover.Profile, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read coverage directory %v %v", dir, err)
+	}
+
+	metas := make(map[[16]byte]metaFile)
+	counters := make([]counterFile, 0)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+
+		switch {
+		case strings.HasPrefix(name, metaFilePrefix):
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+
+			mf, err := parseMetaFile(b)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode meta-data file %v %v", path, err)
+			}
+
+			metas[mf.Hash] = mf
+		case strings.HasPrefix(name, counterFilePrefix):
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+
+			cf, err := parseCounterFile(b)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode counter data file %v %v", path, err)
+			}
+
+			counters = append(counters, cf)
+		default:
+			log.Debugf("skipping file %v in coverage directory", path)
+		}
+	}
+
+	if len(metas) == 0 {
+		return nil, fmt.Errorf("no coverage meta-data files found in %v", dir)
+	}
+
+	sets := make([][]*cover.Profile, 0, len(metas)+len(counters))
+
+	// every unit is included with a count of zero so that functions which never ran are still reported
+	for _, mf := range metas {
+		sets = append(sets, unitProfiles(mf, nil))
+	}
+
+	for _, cf := range counters {
+		mf, ok := metas[cf.MetaHash]
+		if !ok {
+			return nil, fmt.Errorf("no meta-data file in %v for counter data with hash %x", dir, cf.MetaHash)
+		}
+
+		sets = append(sets, unitProfiles(mf, cf.Functions))
+	}
+
+	return profile.MergeProfiles(sets...)
+}
+
+// unitProfiles returns a profile for each source file of the meta-data. If counters is nil every unit of every
+// function is included with a count of zero, otherwise only the units of functions with counters are included.
+func unitProfiles(mf metaFile, counters []functionCounters) []*cover.Profile {
+	profiles := make(map[string]*cover.Profile)
+	out := make([]*cover.Profile, 0)
+
+	add := func(fn metaFunction, counts []uint32) {
+		p, ok := profiles[fn.SrcFile]
+		if !ok {
+			p = &cover.Profile{FileName: fn.SrcFile, Mode: mf.Mode}
+			profiles[fn.SrcFile] = p
+			out = append(out, p)
+		}
+
+		for i, u := range fn.Units {
+			p.Blocks = append(p.Blocks, cover.ProfileBlock{
+				StartLine: u.StartLine,
+				StartCol:  u.StartCol,
+				EndLine:   u.EndLine,
+				EndCol:    u.EndCol,
+				NumStmt:   u.NumStmt,
+				Count:     unitCount(counts, i, mf.Granularity),
+			})
+		}
+	}
+
+	if counters == nil {
+		for _, pkg := range mf.Packages {
+			for _, fn := range pkg.Functions {
+				add(fn, nil)
+			}
+		}
+
+		return out
+	}
+
+	for _, fc := range counters {
+		if int(fc.PkgIdx) >= len(mf.Packages) || int(fc.FuncIdx) >= len(mf.Packages[fc.PkgIdx].Functions) {
+			log.Debugf("skipping counters for unknown function %v of package %v", fc.FuncIdx, fc.PkgIdx)
+			continue
+		}
+
+		add(mf.Packages[fc.PkgIdx].Functions[fc.FuncIdx], fc.Counters)
+	}
+
+	return out
+}
+
+// unitCount returns the count of the unit at index i. Binaries built with per function granularity have a single
+// counter for all units of a function.
+func unitCount(counts []uint32, i int, granularity uint8) int {
+	if granularity == granularityPerFunc {
+		i = 0
+	}
+
+	if i >= len(counts) {
+		return 0
+	}
+
+	return int(counts[i])
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverdir
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+)
+
+// testdata holds the coverage data of two runs of a binary built with go build -cover -covermode=count
+const expectedProfile = `mode: count
+example.com/covmod/lib/lib.go:4.2,4.13 1 2
+example.com/covmod/lib/lib.go:5.3,6.1 1 0
+example.com/covmod/lib/lib.go:7.2,7.14 1 2
+example.com/covmod/lib/lib.go:11.2,12.1 1 0
+example.com/covmod/main.go:9.2,9.23 1 2
+example.com/covmod/main.go:9.25,9.39 1 2
+example.com/covmod/main.go:10.2,10.31 1 2
+`
+
+func Test_ReadProfiles(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	profiles, err := ReadProfiles("testdata")
+	g.Expect(err).To(BeNil())
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(profile.WriteProfiles(buf, profiles)).To(Succeed())
+	g.Expect(buf.String()).To(Equal(expectedProfile))
+}
+
+func Test_ReadProfiles_Errors(t *testing.T) {
+	metaName := "covmeta.14afcf748814a4c734b42ff6ec7c62bb"
+	counterName := "covcounters.14afcf748814a4c734b42ff6ec7c62bb.14910.1792174397956870099"
+
+	meta, err := ioutil.ReadFile(filepath.Join("testdata", metaName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counters, err := ioutil.ReadFile(filepath.Join("testdata", counterName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]map[string][]byte{
+		"empty directory":            {},
+		"counters without meta-data": {counterName: counters},
+		"truncated meta-data":        {metaName: meta[:len(meta)/2]},
+		"truncated counters":         {metaName: meta, counterName: counters[:len(counters)-20]},
+		"meta-data with bad magic":   {metaName: append([]byte("meow"), meta[4:]...)},
+		"counters with bad magic":    {metaName: meta, counterName: append([]byte("meow"), counters[4:]...)},
+	}
+
+	for desc := range testCases {
+		desc := desc
+		t.Run(desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			dir, err := ioutil.TempDir("", "coverdir")
+			g.Expect(err).To(BeNil())
+
+			defer os.RemoveAll(dir)
+
+			for name, content := range testCases[desc] {
+				g.Expect(ioutil.WriteFile(filepath.Join(dir, name), content, 0644)).To(Succeed())
+			}
+
+			_, err = ReadProfiles(dir)
+			g.Expect(err).ToNot(BeNil())
+		})
+	}
+
+	g := NewGomegaWithT(t)
+	_, err = ReadProfiles(filepath.Join("testdata", "missing"))
+	g.Expect(err).ToNot(BeNil())
+}
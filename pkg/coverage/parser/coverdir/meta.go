@@ -0,0 +1,235 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverdir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+var metaMagic = []byte{0x00, 0x63, 0x76, 0x6d}
+
+const (
+	metaFileVersion = 1
+	// metaFileHeaderSize is the size of the header of a meta-data file
+	metaFileHeaderSize = 56
+	// metaPackageHeaderSize is the size of the header of each package in a meta-data file
+	metaPackageHeaderSize = 44
+)
+
+// counter modes of the meta-data file header
+const (
+	modeSet    = 1
+	modeCount  = 2
+	modeAtomic = 3
+)
+
+// counter granularities of the meta-data file header
+const (
+	granularityPerBlock = 1
+	granularityPerFunc  = 2
+)
+
+type metaFile struct {
+	Hash        [16]byte
+	Mode        string
+	Granularity uint8
+	Packages    []metaPackage
+}
+
+type metaPackage struct {
+	Path      string
+	Functions []metaFunction
+}
+
+type metaFunction struct {
+	Name    string
+	SrcFile string
+	Units   []coverableUnit
+}
+
+// coverableUnit is a block of source with a single counter, the same as a block of a text coverage profile
+type coverableUnit struct {
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+}
+
+// parseMetaFile decodes a covmeta file written by a binary built with -cover
+func parseMetaFile(b []byte) (metaFile, error) {
+	r := newByteReader(b)
+
+	if !bytes.Equal(r.next(4), metaMagic) {
+		return metaFile{}, fmt.Errorf("not a coverage meta-data file")
+	}
+
+	if version := r.uint32(binary.LittleEndian); version > metaFileVersion {
+		return metaFile{}, fmt.Errorf("unsupported meta-data file version %v", version)
+	}
+
+	totalLength := r.uint64()
+	entries := r.uint64()
+
+	mf := metaFile{}
+	copy(mf.Hash[:], r.next(16))
+
+	r.next(8) // string table offset and length, the file string table is not needed
+	mode := r.uint8()
+	mf.Granularity = r.uint8()
+
+	if r.err != nil {
+		return metaFile{}, r.err
+	}
+
+	if totalLength != uint64(len(b)) || entries > totalLength {
+		return metaFile{}, fmt.Errorf("malformed meta-data file header")
+	}
+
+	var err error
+	if mf.Mode, err = modeName(mode); err != nil {
+		return metaFile{}, err
+	}
+
+	r.seek(metaFileHeaderSize)
+
+	offsets := make([]uint64, entries)
+	for i := range offsets {
+		offsets[i] = r.uint64()
+	}
+
+	lengths := make([]uint64, entries)
+	for i := range lengths {
+		lengths[i] = r.uint64()
+	}
+
+	if r.err != nil {
+		return metaFile{}, r.err
+	}
+
+	for i := range offsets {
+		if offsets[i]+lengths[i] > totalLength {
+			return metaFile{}, fmt.Errorf("malformed offset for package %v", i)
+		}
+
+		pkg, err := parseMetaPackage(b[offsets[i] : offsets[i]+lengths[i]])
+		if err != nil {
+			return metaFile{}, err
+		}
+
+		mf.Packages = append(mf.Packages, pkg)
+	}
+
+	return mf, nil
+}
+
+func modeName(mode uint8) (string, error) {
+	switch mode {
+	case modeSet:
+		return "set", nil
+	case modeCount:
+		return "count", nil
+	case modeAtomic:
+		return "atomic", nil
+	}
+
+	return "", fmt.Errorf("unsupported counter mode %v", mode)
+}
+
+// parseMetaPackage decodes the meta-data of a single package. Offsets within the payload are relative to its start.
+func parseMetaPackage(b []byte) (metaPackage, error) {
+	r := newByteReader(b)
+
+	r.next(8) // payload length and package name
+	pkgPathIdx := r.uint32(binary.LittleEndian)
+	r.next(4 + 16 + 4) // module path, hash and padding
+	r.next(4)          // number of files
+	numFuncs := r.uint32(binary.LittleEndian)
+
+	if r.err != nil {
+		return metaPackage{}, r.err
+	}
+
+	funcOffsets := make([]uint32, numFuncs)
+	for i := range funcOffsets {
+		funcOffsets[i] = r.uint32(binary.LittleEndian)
+	}
+
+	strs := r.stringTable()
+	if r.err != nil {
+		return metaPackage{}, r.err
+	}
+
+	pkgPath, err := lookupString(strs, uint64(pkgPathIdx))
+	if err != nil {
+		return metaPackage{}, err
+	}
+
+	pkg := metaPackage{Path: pkgPath, Functions: make([]metaFunction, 0, numFuncs)}
+
+	for _, off := range funcOffsets {
+		r.seek(int(off))
+
+		fn, err := parseMetaFunction(r, strs)
+		if err != nil {
+			return metaPackage{}, fmt.Errorf("could not decode function of package %v %v", pkgPath, err)
+		}
+
+		pkg.Functions = append(pkg.Functions, fn)
+	}
+
+	return pkg, nil
+}
+
+func parseMetaFunction(r *byteReader, strs []string) (metaFunction, error) {
+	numUnits := r.uleb128()
+	nameIdx := r.uleb128()
+	fileIdx := r.uleb128()
+
+	if r.err != nil {
+		return metaFunction{}, r.err
+	}
+
+	if numUnits > uint64(len(r.b)) {
+		return metaFunction{}, fmt.Errorf("malformed unit count %v", numUnits)
+	}
+
+	fn := metaFunction{Units: make([]coverableUnit, 0, numUnits)}
+
+	var err error
+	if fn.Name, err = lookupString(strs, nameIdx); err != nil {
+		return metaFunction{}, err
+	}
+
+	if fn.SrcFile, err = lookupString(strs, fileIdx); err != nil {
+		return metaFunction{}, err
+	}
+
+	for i := uint64(0); i < numUnits; i++ {
+		fn.Units = append(fn.Units, coverableUnit{
+			StartLine: int(r.uleb128()),
+			StartCol:  int(r.uleb128()),
+			EndLine:   int(r.uleb128()),
+			EndCol:    int(r.uleb128()),
+			NumStmt:   int(r.uleb128()),
+		})
+	}
+
+	r.uleb128() // whether the function is a literal
+
+	return fn, r.err
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverdir
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// byteReader reads the little endian and uleb128 encoded values of the coverage data formats from a byte slice. The
+// first out of bounds read is kept in err and every read after it returns zero values.
+type byteReader struct {
+	b   []byte
+	off int
+	err error
+}
+
+func newByteReader(b []byte) *byteReader {
+	return &byteReader{b: b}
+}
+
+func (r *byteReader) seek(off int) {
+	if r.err == nil && (off < 0 || off > len(r.b)) {
+		r.err = fmt.Errorf("offset %v is out of bounds", off)
+		return
+	}
+
+	r.off = off
+}
+
+func (r *byteReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if n < 0 || r.off+n > len(r.b) {
+		r.err = fmt.Errorf("unexpected end of data reading %v bytes at offset %v", n, r.off)
+		return nil
+	}
+
+	out := r.b[r.off : r.off+n]
+	r.off += n
+
+	return out
+}
+
+func (r *byteReader) uint8() uint8 {
+	b := r.next(1)
+	if b == nil {
+		return 0
+	}
+
+	return b[0]
+}
+
+func (r *byteReader) uint32(order binary.ByteOrder) uint32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+
+	return order.Uint32(b)
+}
+
+func (r *byteReader) uint64() uint64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (r *byteReader) uleb128() uint64 {
+	var (
+		value uint64
+		shift uint
+	)
+
+	for {
+		b := r.next(1)
+		if b == nil {
+			return 0
+		}
+
+		value |= uint64(b[0]&0x7f) << shift
+		if b[0]&0x80 == 0 {
+			return value
+		}
+
+		shift += 7
+	}
+}
+
+// stringTable reads a table of strings, a uleb128 count followed by the uleb128 length and bytes of each string
+func (r *byteReader) stringTable() []string {
+	count := r.uleb128()
+	if r.err != nil || count > uint64(len(r.b)) {
+		r.err = fmt.Errorf("malformed string table")
+		return nil
+	}
+
+	out := make([]string, 0, count)
+
+	for i := uint64(0); i < count; i++ {
+		length := r.uleb128()
+		if length > uint64(len(r.b)) {
+			r.err = fmt.Errorf("malformed string table")
+			return nil
+		}
+
+		out = append(out, string(r.next(int(length))))
+	}
+
+	return out
+}
+
+func lookupString(table []string, idx uint64) (string, error) {
+	if idx >= uint64(len(table)) {
+		return "", fmt.Errorf("string table index %v is out of range", idx)
+	}
+
+	return table[idx], nil
+}
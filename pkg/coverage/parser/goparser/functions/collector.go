@@ -15,8 +15,11 @@
 package functions
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
+	"strings"
 
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
 	log "github.com/sirupsen/logrus"
@@ -24,55 +27,23 @@ import (
 
 func CollectFunctions(f *ast.File, fset *token.FileSet, filePath string) ([]Function, error) {
 	functions := []Function{}
+	comments := f.Comments
 
 	for i := range f.Decls {
 		switch x := f.Decls[i].(type) {
 		case *ast.FuncDecl:
-			name := x.Name.Name
-
-			start := fset.Position(x.Pos())
-			end := fset.Position(x.End())
-			startLine := start.Line
-			startCol := start.Column
-			endLine := end.Line
-			endCol := end.Column
-			f := Function{
-				Name:        name,
-				StartLine:   startLine,
-				StartCol:    startCol,
-				EndLine:     endLine,
-				EndCol:      endCol,
-				SrcPath:     filePath,
-				StartOffset: start.Offset,
-				EndOffset:   end.Offset,
-			}
+			// regions are found before collecting statements since the collector adjusts the positions of else blocks
+			ignored := ignoredRegions(x, comments, fset)
+
+			receiver := receiverType(x)
 
-			sc := &statements.StmtCollector{}
-			if err := sc.Collect(x.Body, fset); err != nil {
+			f, err := newFunction(qualifiedName(receiver, x.Name.Name), x, x.Body, fset, filePath, ignored, false)
+			if err != nil {
 				return nil, err
 			}
 
-			stmts := sc.Statements
-			log.Debugf("statements for function %v %v", f.Name, stmts)
-			convertedStmts := make([]statements.Statement, 0, len(stmts))
-
-			for _, stmnt := range stmts {
-				start := fset.Position(stmnt.Pos())
-				end := fset.Position(stmnt.End())
-				startLine := start.Line
-				startCol := start.Column
-				endLine := end.Line
-				endCol := end.Column
-				s := statements.Statement{
-					StartLine: int64(startLine),
-					StartCol:  int64(startCol),
-					EndLine:   int64(endLine),
-					EndCol:    int64(endCol),
-				}
-				convertedStmts = append(convertedStmts, s)
-			}
+			f.Receiver = receiver
 
-			f.Statements = convertedStmts
 			functions = append(functions, f)
 		}
 	}
@@ -81,3 +52,188 @@ func CollectFunctions(f *ast.File, fset *token.FileSet, filePath string) ([]Func
 
 	return functions, nil
 }
+
+// receiverType returns the type of the receiver of a method, including any type parameters, such as *List[T]. It
+// returns an empty string for functions.
+func receiverType(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+
+	return types.ExprString(decl.Recv.List[0].Type)
+}
+
+// qualifiedName returns the name of a method qualified by its receiver type the way the Go toolchain writes it, such
+// as (*Server).Start or Server.Start. Functions without a receiver keep their name.
+func qualifiedName(receiver, name string) string {
+	if receiver == "" {
+		return name
+	}
+
+	if strings.HasPrefix(receiver, "*") {
+		return fmt.Sprintf("(%v).%v", receiver, name)
+	}
+
+	return fmt.Sprintf("%v.%v", receiver, name)
+}
+
+// newFunction builds the function for node along with the closures defined in its body
+func newFunction(
+	name string,
+	node ast.Node,
+	body *ast.BlockStmt,
+	fset *token.FileSet,
+	filePath string,
+	ignored []IgnoredRegion,
+	isClosure bool,
+) (Function, error) {
+	start := fset.Position(node.Pos())
+	end := fset.Position(node.End())
+	f := Function{
+		Name:        name,
+		StartLine:   start.Line,
+		StartCol:    start.Column,
+		EndLine:     end.Line,
+		EndCol:      end.Column,
+		SrcPath:     filePath,
+		StartOffset: start.Offset,
+		EndOffset:   end.Offset,
+		Ignored:     ignored,
+		Complexity:  complexity(body),
+	}
+
+	if body == nil {
+		return f, nil
+	}
+
+	sc := &statements.StmtCollector{}
+	if err := sc.Collect(body, fset); err != nil {
+		return Function{}, err
+	}
+
+	stmts := sc.Statements
+	log.Debugf("statements for function %v %v", f.Name, stmts)
+	convertedStmts := make([]statements.Statement, 0, len(stmts))
+
+	for _, stmnt := range stmts {
+		start := fset.Position(stmnt.Pos())
+		end := fset.Position(stmnt.End())
+		s := statements.Statement{
+			StartLine: int64(start.Line),
+			StartCol:  int64(start.Column),
+			EndLine:   int64(end.Line),
+			EndCol:    int64(end.Column),
+		}
+		convertedStmts = append(convertedStmts, s)
+	}
+
+	f.Statements, f.IgnoredStatements = splitIgnoredStatements(convertedStmts, f.Ignored)
+	f.Branches = dropIgnoredBranches(sc.Branches, f.Ignored)
+
+	closures, err := collectClosures(f, body, fset, isClosure)
+	if err != nil {
+		return Function{}, err
+	}
+
+	f.Closures = closures
+
+	return f, nil
+}
+
+// collectClosures returns the function literals directly within body, named the way the Go toolchain names them.
+// Literals in a function declaration are named Outer.func1, Outer.func2 and literals nested in those Outer.func1.1.
+func collectClosures(parent Function, body *ast.BlockStmt, fset *token.FileSet, nested bool) ([]Function, error) {
+	lits := make([]*ast.FuncLit, 0)
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		lit, ok := n.(*ast.FuncLit)
+		if ok {
+			lits = append(lits, lit)
+		}
+
+		// literals within a literal are collected as its own closures
+		return !ok
+	})
+
+	if len(lits) == 0 {
+		return nil, nil
+	}
+
+	closures := make([]Function, 0, len(lits))
+
+	for i, lit := range lits {
+		name := fmt.Sprintf("%v.func%d", parent.Name, i+1)
+		if nested {
+			name = fmt.Sprintf("%v.%d", parent.Name, i+1)
+		}
+
+		closure, err := newFunction(name, lit, lit.Body, fset, parent.SrcPath, parent.Ignored, true)
+		if err != nil {
+			return nil, err
+		}
+
+		closures = append(closures, closure)
+	}
+
+	return closures, nil
+}
+
+// dropIgnoredBranches returns the branches which do not start within an ignored region
+func dropIgnoredBranches(branches []statements.Branch, ignored []IgnoredRegion) []statements.Branch {
+	if len(ignored) == 0 {
+		return branches
+	}
+
+	kept := make([]statements.Branch, 0, len(branches))
+
+	for _, b := range branches {
+		isIgnored := false
+
+		for _, r := range ignored {
+			if r.ContainsPosition(int(b.Body.StartLine), int(b.Body.StartCol)) {
+				isIgnored = true
+				break
+			}
+		}
+
+		if !isIgnored {
+			kept = append(kept, b)
+		}
+	}
+
+	return kept
+}
+
+// splitIgnoredStatements separates the statements which start within an ignored region from the rest
+func splitIgnoredStatements(
+	stmts []statements.Statement,
+	ignored []IgnoredRegion,
+) ([]statements.Statement, []statements.Statement) {
+	if len(ignored) == 0 {
+		return stmts, nil
+	}
+
+	kept := make([]statements.Statement, 0, len(stmts))
+
+	var dropped []statements.Statement
+
+	for _, s := range stmts {
+		isIgnored := false
+
+		for _, r := range ignored {
+			if r.ContainsPosition(int(s.StartLine), int(s.StartCol)) {
+				isIgnored = true
+				break
+			}
+		}
+
+		if isIgnored {
+			dropped = append(dropped, s)
+			continue
+		}
+
+		kept = append(kept, s)
+	}
+
+	return kept, dropped
+}
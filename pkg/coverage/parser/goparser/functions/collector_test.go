@@ -63,6 +63,146 @@ func Meow(x, y int) bool {
 	g.Expect(funcs).To(HaveLen(1))
 }
 
+func Test_CollectFunctions_IgnoreDirectives(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	src := `package foo
+
+// Dump prints state
+//gocheckcov:ignore debug helper
+func Dump(x int) {
+	println(x)
+}
+
+func Check(x int) int {
+	if x < 0 {
+		panic("unreachable") //gocheckcov:ignore validated by caller
+	}
+	//gocheckcov:ignore
+	if x > 100 {
+		return 100
+	}
+	return x
+}
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", []byte(src), parser.ParseComments)
+	g.Expect(err).To(BeNil())
+
+	funcs, err := CollectFunctions(f, fset, "foo.go")
+	g.Expect(err).To(BeNil())
+	g.Expect(funcs).To(HaveLen(2))
+
+	dump := funcs[0]
+	g.Expect(dump.Ignored).To(Equal([]IgnoredRegion{
+		{StartLine: 5, StartCol: 1, EndLine: 7, EndCol: 2, Reason: "debug helper"},
+	}))
+	g.Expect(dump.Statements).To(BeEmpty())
+	g.Expect(dump.IgnoredStatements).To(HaveLen(1))
+
+	check := funcs[1]
+	g.Expect(check.Ignored).To(Equal([]IgnoredRegion{
+		{StartLine: 11, StartCol: 3, EndLine: 11, EndCol: 23, Reason: "validated by caller"},
+		{StartLine: 14, StartCol: 2, EndLine: 16, EndCol: 3},
+	}))
+	g.Expect(check.Statements).To(HaveLen(2))
+	g.Expect(check.IgnoredStatements).To(HaveLen(3))
+}
+
+func Test_CollectFunctions_Closures(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	src := `package foo
+
+func Serve(xs []int) {
+	handle := func(x int) {
+		go func() {
+			println(x)
+		}()
+	}
+	for _, x := range xs {
+		handle(x)
+	}
+	defer func() { recover() }()
+}
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", []byte(src), parser.ParseComments)
+	g.Expect(err).To(BeNil())
+
+	funcs, err := CollectFunctions(f, fset, "foo.go")
+	g.Expect(err).To(BeNil())
+	g.Expect(funcs).To(HaveLen(1))
+
+	serve := funcs[0]
+	g.Expect(serve.Statements).To(HaveLen(4))
+	g.Expect(serve.Closures).To(HaveLen(2))
+
+	handle := serve.Closures[0]
+	g.Expect(handle.Name).To(Equal("Serve.func1"))
+	g.Expect(handle.StartLine).To(Equal(4))
+	g.Expect(handle.EndLine).To(Equal(8))
+	g.Expect(handle.Statements).To(HaveLen(1))
+	g.Expect(handle.Closures).To(HaveLen(1))
+	g.Expect(handle.Closures[0].Name).To(Equal("Serve.func1.1"))
+	g.Expect(handle.Closures[0].Statements).To(HaveLen(1))
+	g.Expect(handle.Closures[0].Closures).To(BeEmpty())
+
+	g.Expect(serve.Closures[1].Name).To(Equal("Serve.func2"))
+	g.Expect(serve.Closures[1].Statements).To(HaveLen(1))
+}
+
+func Test_CollectFunctions_MethodNames(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	src := `package foo
+
+type Server struct{}
+
+func (s *Server) Start() { go func() {}() }
+
+func (Server) Name() string { return "server" }
+
+type List[T any] struct{}
+
+func (l *List[T]) Push(v T) {}
+
+type Pair[K comparable, V any] struct{}
+
+func (p Pair[K, V]) Key() {}
+
+func Start() {}
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", []byte(src), parser.ParseComments)
+	g.Expect(err).To(BeNil())
+
+	funcs, err := CollectFunctions(f, fset, "foo.go")
+	g.Expect(err).To(BeNil())
+
+	names := make([]string, 0, len(funcs))
+	methodNames := make([]string, 0, len(funcs))
+
+	for _, fn := range funcs {
+		names = append(names, fn.Name)
+		methodNames = append(methodNames, fn.MethodName())
+	}
+
+	g.Expect(names).To(Equal([]string{
+		"(*Server).Start",
+		"Server.Name",
+		"(*List[T]).Push",
+		"Pair[K, V].Key",
+		"Start",
+	}))
+	g.Expect(methodNames).To(Equal([]string{"Start", "Name", "Push", "Key", "Start"}))
+	g.Expect(funcs[0].Receiver).To(Equal("*Server"))
+	g.Expect(funcs[0].Closures[0].Name).To(Equal("(*Server).Start.func1"))
+}
+
 //func MatchFunc(expected Function) types.GomegaMatcher {
 //  return &funcMatcher{
 //    expected: expected,
@@ -126,3 +266,51 @@ func Meow(x, y int) bool {
 //func (f *funcMatcher) NegatedFailureMessage(actual interface{}) (message string) {
 //  return fmt.Sprintf("expected %v to not equal %v", f.expected, actual)
 //}
+
+func Test_CollectFunctions_Complexity(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	src := `package foo
+
+func Get() int { return 1 }
+
+func Parse(xs []string, ch chan int) int {
+	n := 0
+	for _, x := range xs {
+		if x == "" || x == "-" {
+			continue
+		}
+		switch x {
+		case "a", "b":
+			n++
+		default:
+			n--
+		}
+	}
+	select {
+	case v := <-ch:
+		n += v
+	default:
+	}
+	check := func() bool { return n > 0 && n < 10 }
+	if check() {
+		return n
+	}
+	return 0
+}
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", []byte(src), parser.ParseComments)
+	g.Expect(err).To(BeNil())
+
+	funcs, err := CollectFunctions(f, fset, "foo.go")
+	g.Expect(err).To(BeNil())
+	g.Expect(funcs).To(HaveLen(2))
+
+	g.Expect(funcs[0].Complexity).To(Equal(1))
+	// range, if, ||, case, comm case, if and the && of the closure
+	g.Expect(funcs[1].Complexity).To(Equal(8))
+	g.Expect(funcs[1].Closures).To(HaveLen(1))
+	g.Expect(funcs[1].Closures[0].Complexity).To(Equal(2))
+}
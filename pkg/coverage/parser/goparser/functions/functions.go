@@ -14,10 +14,18 @@
 
 package functions
 
-import "github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
+import (
+	"strings"
 
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
+)
+
+// Function is a function declaration or function literal in a source file. Methods are named with their receiver
+// type, such as (*Server).Start, and closures after the function they are defined in, such as Serve.func1.
 type Function struct {
-	Name        string
+	Name string
+	// Receiver is the receiver type of a method, such as *Server, and empty for functions and closures
+	Receiver    string
 	SrcPath     string
 	StartOffset int
 	StartLine   int
@@ -26,4 +34,24 @@ type Function struct {
 	EndLine     int
 	EndCol      int
 	Statements  []statements.Statement
+	// Ignored holds the regions of the function that are left out of coverage by ignore directives
+	Ignored []IgnoredRegion
+	// IgnoredStatements holds the statements within Ignored, which are not included in Statements
+	IgnoredStatements []statements.Statement
+	// Branches holds the branches of the decisions in the function, except those within Ignored
+	Branches []statements.Branch
+	// Complexity is the cyclomatic complexity of the function, including the closures defined within it
+	Complexity int
+	// Closures holds the function literals defined directly within the function. Their statements are not included
+	// in Statements.
+	Closures []Function
+}
+
+// MethodName returns the name of a method without its receiver type. It returns Name for functions and closures.
+func (f Function) MethodName() string {
+	if f.Receiver == "" {
+		return f.Name
+	}
+
+	return strings.TrimPrefix(f.Name, qualifiedName(f.Receiver, ""))
 }
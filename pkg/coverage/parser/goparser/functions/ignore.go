@@ -0,0 +1,145 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package functions
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// IgnoreDirective leaves code out of coverage. In the doc comment of a function it ignores the whole function. Inside
+// a function body it ignores the statement on the same line, or the statement on the next line when the directive is
+// the only thing on its line. Any text following the directive is kept as the reason.
+const IgnoreDirective = "//gocheckcov:ignore"
+
+// IgnoredRegion is a range of source that is left out of coverage because of an ignore directive
+type IgnoredRegion struct {
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	Reason    string
+}
+
+// Contains reports whether the range from start to end lies entirely within the region
+func (r IgnoredRegion) Contains(startLine, startCol, endLine, endCol int) bool {
+	return !positionBefore(startLine, startCol, r.StartLine, r.StartCol) &&
+		!positionBefore(r.EndLine, r.EndCol, endLine, endCol)
+}
+
+// ContainsPosition reports whether the position is within the region
+func (r IgnoredRegion) ContainsPosition(line, col int) bool {
+	return r.Contains(line, col, line, col)
+}
+
+func positionBefore(line, col, otherLine, otherCol int) bool {
+	return line < otherLine || (line == otherLine && col < otherCol)
+}
+
+// parseDirective returns the reason given with an ignore directive and whether the comment is an ignore directive
+func parseDirective(c *ast.Comment) (string, bool) {
+	if c.Text == IgnoreDirective {
+		return "", true
+	}
+
+	if strings.HasPrefix(c.Text, IgnoreDirective+" ") {
+		return strings.TrimSpace(strings.TrimPrefix(c.Text, IgnoreDirective)), true
+	}
+
+	return "", false
+}
+
+// ignoredRegions returns the regions of the function which are ignored by directives. comments are all of the
+// comments of the file the function belongs to.
+func ignoredRegions(decl *ast.FuncDecl, comments []*ast.CommentGroup, fset *token.FileSet) []IgnoredRegion {
+	if decl.Doc != nil {
+		for _, c := range decl.Doc.List {
+			if reason, ok := parseDirective(c); ok {
+				return []IgnoredRegion{newIgnoredRegion(decl, reason, fset)}
+			}
+		}
+	}
+
+	if decl.Body == nil {
+		return nil
+	}
+
+	stmtOnLine := outermostStatementsByLine(decl.Body, fset)
+
+	var regions []IgnoredRegion
+
+	for _, cg := range comments {
+		if cg.Pos() < decl.Body.Pos() || cg.End() > decl.Body.End() {
+			continue
+		}
+
+		for _, c := range cg.List {
+			reason, ok := parseDirective(c)
+			if !ok {
+				continue
+			}
+
+			pos := fset.Position(c.Pos())
+
+			stmt, ok := stmtOnLine[pos.Line]
+			if !ok || stmt.Pos() > c.Pos() {
+				stmt, ok = stmtOnLine[pos.Line+1]
+			}
+
+			if !ok {
+				continue
+			}
+
+			regions = append(regions, newIgnoredRegion(stmt, reason, fset))
+		}
+	}
+
+	return regions
+}
+
+// outermostStatementsByLine maps each line to the outermost statement which starts on it
+func outermostStatementsByLine(body *ast.BlockStmt, fset *token.FileSet) map[int]ast.Stmt {
+	out := make(map[int]ast.Stmt)
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		stmt, ok := n.(ast.Stmt)
+		if !ok || stmt == body {
+			return true
+		}
+
+		line := fset.Position(stmt.Pos()).Line
+		if _, ok := out[line]; !ok {
+			out[line] = stmt
+		}
+
+		return true
+	})
+
+	return out
+}
+
+func newIgnoredRegion(n ast.Node, reason string, fset *token.FileSet) IgnoredRegion {
+	start := fset.Position(n.Pos())
+	end := fset.Position(n.End())
+
+	return IgnoredRegion{
+		StartLine: start.Line,
+		StartCol:  start.Column,
+		EndLine:   end.Line,
+		EndCol:    end.Column,
+		Reason:    reason,
+	}
+}
@@ -32,7 +32,7 @@ func NodeFromFilePath(filePath string, fset *token.FileSet) (*ast.File, error) {
 		return nil, err
 	}
 
-	f, err := parser.ParseFile(fset, pFilePath, src, 0)
+	f, err := parser.ParseFile(fset, pFilePath, src, parser.ParseComments)
 	if err != nil {
 		log.Debugf("could not parse file %v %v", pFilePath, err)
 		return nil, err
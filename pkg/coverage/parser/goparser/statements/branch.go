@@ -0,0 +1,111 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statements
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+const (
+	BranchIf           = "if"
+	BranchElse         = "else"
+	BranchImplicitElse = "implicit else"
+	BranchCase         = "case"
+	BranchComm         = "comm"
+)
+
+// Branch is one of the paths a decision can take: the body of an if statement or its else, the else of an if
+// statement without one, or a case of a switch or select statement
+type Branch struct {
+	Kind string
+	// Body is the code the branch runs. For an implicit else it is the if statement.
+	Body Statement
+	// Terminates is true for an implicit else when the body of the if statement ends with a return, panic or branch
+	// statement, so the statement after the if only runs when the implicit else is taken
+	Terminates bool
+	// Next is the start of the statement after the if statement of an implicit else, if there is one
+	Next *Statement
+}
+
+func newStatement(start, end token.Pos, fset *token.FileSet) Statement {
+	startPos := fset.Position(start)
+	endPos := fset.Position(end)
+
+	return Statement{
+		StartOffset: int64(startPos.Offset),
+		StartLine:   int64(startPos.Line),
+		StartCol:    int64(startPos.Column),
+		EndOffset:   int64(endPos.Offset),
+		EndLine:     int64(endPos.Line),
+		EndCol:      int64(endPos.Column),
+	}
+}
+
+// addIfBranches adds the branches of an if statement. It must be called after the statement is collected, since
+// collecting adjusts the else block to start at the else keyword the same way the cover tool does.
+func (sc *StmtCollector) addIfBranches(s *ast.IfStmt, next ast.Stmt, fset *token.FileSet) {
+	if s.Body == nil {
+		return
+	}
+
+	sc.Branches = append(sc.Branches, Branch{Kind: BranchIf, Body: newStatement(s.Body.Lbrace, s.Body.End(), fset)})
+
+	if s.Else != nil {
+		sc.Branches = append(sc.Branches, Branch{Kind: BranchElse, Body: newStatement(s.Else.Pos(), s.Else.End(), fset)})
+		return
+	}
+
+	b := Branch{
+		Kind:       BranchImplicitElse,
+		Body:       newStatement(s.Pos(), s.End(), fset),
+		Terminates: terminates(s.Body),
+	}
+
+	if next != nil {
+		n := newStatement(next.Pos(), next.End(), fset)
+		b.Next = &n
+	}
+
+	sc.Branches = append(sc.Branches, b)
+}
+
+func (sc *StmtCollector) addClauseBranch(kind string, colon token.Pos, end token.Pos, fset *token.FileSet) {
+	// the cover tool starts the block of a clause right after its colon
+	sc.Branches = append(sc.Branches, Branch{Kind: kind, Body: newStatement(colon+1, end, fset)})
+}
+
+// terminates reports whether the block ends with a statement which leaves it
+func terminates(block *ast.BlockStmt) bool {
+	if len(block.List) == 0 {
+		return false
+	}
+
+	switch s := block.List[len(block.List)-1].(type) {
+	case *ast.ReturnStmt, *ast.BranchStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+
+		ident, ok := call.Fun.(*ast.Ident)
+
+		return ok && ident.Name == "panic"
+	}
+
+	return false
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statements
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_CollectBranches(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	src := `package foo
+
+func F(x int) int {
+	if x > 10 {
+	}
+	if x > 5 {
+		return 1
+	} else if x > 2 {
+		x++
+	} else {
+		x--
+	}
+	switch x {
+	case 1:
+	default:
+		x = 0
+	}
+	if x < 0 {
+		return -1
+	}
+	return x
+}
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", []byte(src), 0)
+	g.Expect(err).To(BeNil())
+
+	fn, ok := f.Decls[0].(*ast.FuncDecl)
+	g.Expect(ok).To(BeTrue())
+
+	sc := &StmtCollector{}
+	g.Expect(sc.Collect(fn.Body, fset)).To(BeNil())
+
+	kinds := make([]string, 0, len(sc.Branches))
+	for _, b := range sc.Branches {
+		kinds = append(kinds, b.Kind)
+	}
+
+	g.Expect(kinds).To(Equal([]string{
+		BranchIf, BranchImplicitElse,
+		BranchIf, BranchElse,
+		BranchIf, BranchElse,
+		BranchCase, BranchCase,
+		BranchIf, BranchImplicitElse,
+	}))
+
+	// the first if statement falls through to the next statement, the last one returns before it
+	g.Expect(sc.Branches[1].Terminates).To(BeFalse())
+	g.Expect(sc.Branches[1].Next.StartLine).To(Equal(int64(6)))
+	g.Expect(sc.Branches[9].Terminates).To(BeTrue())
+	g.Expect(sc.Branches[9].Next.StartLine).To(Equal(int64(21)))
+
+	// the else of an if statement starts at the else keyword and a case right after its colon
+	g.Expect(sc.Branches[5].Body.StartLine).To(Equal(int64(8)))
+	g.Expect(sc.Branches[5].Body.StartCol).To(Equal(int64(4)))
+	g.Expect(sc.Branches[6].Body.StartLine).To(Equal(int64(14)))
+	g.Expect(sc.Branches[6].Body.StartCol).To(Equal(int64(9)))
+}
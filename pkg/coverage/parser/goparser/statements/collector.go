@@ -22,6 +22,8 @@ import (
 
 type StmtCollector struct {
 	Statements []ast.Stmt
+	// Branches holds the branches of the decisions among the statements
+	Branches []Branch
 }
 
 func (sc *StmtCollector) Collect(s ast.Stmt, fset *token.FileSet) error {
@@ -36,8 +38,12 @@ func (sc *StmtCollector) Collect(s ast.Stmt, fset *token.FileSet) error {
 		statements = s.List
 	case *ast.CaseClause:
 		statements = s.Body
+
+		sc.addClauseBranch(BranchCase, s.Colon, s.End(), fset)
 	case *ast.CommClause:
 		statements = s.Body
+
+		sc.addClauseBranch(BranchComm, s.Colon, s.End(), fset)
 	default:
 		if err := sc.descend(s, fset); err != nil {
 			return err
@@ -69,6 +75,15 @@ func (sc *StmtCollector) filterStatements(statements []ast.Stmt, fset *token.Fil
 		if err := sc.Collect(s, fset); err != nil {
 			return err
 		}
+
+		if ifStmt, ok := s.(*ast.IfStmt); ok {
+			var next ast.Stmt
+			if i+1 < len(statements) {
+				next = statements[i+1]
+			}
+
+			sc.addIfBranches(ifStmt, next, fset)
+		}
 	}
 
 	return nil
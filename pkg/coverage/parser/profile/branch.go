@@ -0,0 +1,90 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
+	"golang.org/x/tools/cover"
+)
+
+// recordBranchHits counts the branches of the function which were taken according to the blocks of the profile
+func recordBranchHits(branches []statements.Branch, index *blockIndex) int64 {
+	var covered int64
+
+	for _, b := range branches {
+		if branchTaken(b, index) {
+			covered++
+		}
+	}
+
+	return covered
+}
+
+// branchTaken reports whether the branch ran. A branch with a body ran when any block starting within its body was
+// executed. An implicit else ran when the if statement was executed more often than its body, or, for set mode
+// profiles, when the body always leaves the enclosing block and the statement after the if statement was executed.
+func branchTaken(b statements.Branch, index *blockIndex) bool {
+	body := b.Body
+	blocks := index.blocksForLines(int(body.StartLine), int(body.EndLine))
+
+	if b.Kind != statements.BranchImplicitElse {
+		for _, block := range blocks {
+			if block.Count > 0 && startsWithin(block, body) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ifCount, bodyCount := 0, 0
+	bodyFound := false
+
+	for _, block := range blocks {
+		switch {
+		case positionInBlock(block, int(body.StartLine), int(body.StartCol)):
+			ifCount = block.Count
+		case !bodyFound && startsWithin(block, body):
+			// blocks are in order of position so the first block within the if statement is its body
+			bodyCount = block.Count
+			bodyFound = true
+		}
+	}
+
+	if ifCount > bodyCount {
+		return true
+	}
+
+	return b.Terminates && b.Next != nil && executedAt(index, int(b.Next.StartLine), int(b.Next.StartCol))
+}
+
+// startsWithin reports whether the block starts within the range of the statement, including its end
+func startsWithin(block cover.ProfileBlock, s statements.Statement) bool {
+	line, col := block.StartLine, block.StartCol
+	startLine, startCol, endLine, endCol := int(s.StartLine), int(s.StartCol), int(s.EndLine), int(s.EndCol)
+
+	return (line > startLine || (line == startLine && col >= startCol)) &&
+		(line < endLine || (line == endLine && col <= endCol))
+}
+
+func executedAt(index *blockIndex, line, col int) bool {
+	for _, block := range index.blocksForLines(line, line) {
+		if positionInBlock(block, line, col) {
+			return block.Count > 0
+		}
+	}
+
+	return false
+}
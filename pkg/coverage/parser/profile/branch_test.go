@@ -0,0 +1,137 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+func Test_Parser_RecordFunctionCoverage_Branches(t *testing.T) {
+	src := `package foo
+
+func F(x int) int {
+	if x > 10 {
+	}
+	if x > 5 {
+		return 1
+	} else if x > 2 {
+		x++
+	} else {
+		x--
+	}
+	switch x {
+	case 1:
+	case 2:
+		return 2
+	default:
+		x = 0
+	}
+	if x < 0 {
+		return -1
+	}
+	return x
+}
+`
+
+	// blocks as written by go test -coverprofile for the source above with F(3) and F(1) as the test, the counts are
+	// replaced for each testcase
+	blocks := func(counts ...int) []cover.ProfileBlock {
+		out := []cover.ProfileBlock{
+			{StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 12, NumStmt: 1},
+			{StartLine: 4, StartCol: 13, EndLine: 4, EndCol: 13, NumStmt: 0},
+			{StartLine: 6, StartCol: 2, EndLine: 6, EndCol: 11, NumStmt: 1},
+			{StartLine: 7, StartCol: 3, EndLine: 8, EndCol: 1, NumStmt: 1},
+			{StartLine: 8, StartCol: 9, EndLine: 8, EndCol: 18, NumStmt: 1},
+			{StartLine: 9, StartCol: 3, EndLine: 10, EndCol: 1, NumStmt: 1},
+			{StartLine: 11, StartCol: 3, EndLine: 12, EndCol: 1, NumStmt: 1},
+			{StartLine: 13, StartCol: 2, EndLine: 13, EndCol: 11, NumStmt: 1},
+			{StartLine: 14, StartCol: 9, EndLine: 14, EndCol: 9, NumStmt: 0},
+			{StartLine: 16, StartCol: 3, EndLine: 16, EndCol: 11, NumStmt: 1},
+			{StartLine: 18, StartCol: 3, EndLine: 18, EndCol: 8, NumStmt: 1},
+			{StartLine: 20, StartCol: 2, EndLine: 20, EndCol: 11, NumStmt: 1},
+			{StartLine: 21, StartCol: 3, EndLine: 22, EndCol: 1, NumStmt: 1},
+			{StartLine: 23, StartCol: 2, EndLine: 23, EndCol: 10, NumStmt: 1},
+		}
+
+		for i := range out {
+			out[i].Count = counts[i]
+		}
+
+		return out
+	}
+
+	type testcase struct {
+		description     string
+		profile         *cover.Profile
+		expectedCovered int64
+	}
+
+	testCases := []testcase{
+		{
+			description: "no profile",
+		},
+		{
+			description: "count mode",
+			profile: &cover.Profile{
+				Mode:   "count",
+				Blocks: blocks(2, 0, 2, 0, 2, 1, 1, 2, 0, 0, 2, 2, 0, 2),
+			},
+			expectedCovered: 6,
+		},
+		{
+			description: "set mode",
+			profile: &cover.Profile{
+				Mode:   "set",
+				Blocks: blocks(1, 0, 1, 0, 1, 1, 1, 1, 0, 0, 1, 1, 0, 1),
+			},
+			expectedCovered: 6,
+		},
+		{
+			// the implicit else of an if statement which returns ran because the statement after it ran, but for
+			// the first if statement, which falls through, there is no telling
+			description: "set mode with every block run",
+			profile: &cover.Profile{
+				Mode:   "set",
+				Blocks: blocks(1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1),
+			},
+			expectedCovered: 10,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "foo.go", []byte(src), parser.ParseComments)
+			g.Expect(err).To(BeNil())
+
+			funcs, err := functions.CollectFunctions(f, fset, "foo.go")
+			g.Expect(err).To(BeNil())
+
+			fcs := Parser{Profile: tc.profile}.RecordFunctionCoverage(funcs)
+			g.Expect(fcs).To(HaveLen(1))
+			g.Expect(fcs[0].BranchCount).To(Equal(int64(11)))
+			g.Expect(fcs[0].CoveredBranchCount).To(Equal(tc.expectedCovered))
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/cover"
+)
+
+const modeSet = "set"
+
+// ParseProfiles parses and merges the profiles at each path. Paths may be glob patterns, a pattern which matches no
+// files is an error.
+func ParseProfiles(paths []string) ([]*cover.Profile, error) {
+	sets := make([][]*cover.Profile, 0, len(paths))
+
+	for _, pattern := range paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bad profile file pattern %v %v", pattern, err)
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no profile files found for %v", pattern)
+		}
+
+		for _, path := range matches {
+			profiles, err := cover.ParseProfiles(path)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse profiles from %v %v", path, err)
+			}
+
+			sets = append(sets, profiles)
+		}
+	}
+
+	return MergeProfiles(sets...)
+}
+
+type blockKey struct {
+	startLine int
+	startCol  int
+	endLine   int
+	endCol    int
+}
+
+// MergeProfiles merges sets of profiles into one profile per file. Counts of the same block are summed for count and
+// atomic mode profiles, while for set mode profiles a block is covered if it is covered in any profile. Count and
+// atomic mode profiles may be merged with each other and take the mode of the first profile, set mode profiles cannot
+// be merged with either.
+func MergeProfiles(sets ...[]*cover.Profile) ([]*cover.Profile, error) {
+	merged := make(map[string]*cover.Profile)
+	blocks := make(map[string]map[blockKey]int)
+	mode := ""
+
+	for _, profiles := range sets {
+		for _, p := range profiles {
+			if mode == "" {
+				mode = p.Mode
+			}
+
+			if !compatibleModes(mode, p.Mode) {
+				return nil, fmt.Errorf("cannot merge profiles with modes %v and %v for %v", mode, p.Mode, p.FileName)
+			}
+
+			out, ok := merged[p.FileName]
+			if !ok {
+				out = &cover.Profile{FileName: p.FileName, Mode: mode}
+				merged[p.FileName] = out
+				blocks[p.FileName] = make(map[blockKey]int)
+			}
+
+			if err := mergeBlocks(out, blocks[p.FileName], p.Blocks); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	out := make([]*cover.Profile, 0, len(merged))
+
+	for _, p := range merged {
+		sort.Slice(p.Blocks, func(i, j int) bool {
+			bi, bj := p.Blocks[i], p.Blocks[j]
+			return bi.StartLine < bj.StartLine || (bi.StartLine == bj.StartLine && bi.StartCol < bj.StartCol)
+		})
+
+		out = append(out, p)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].FileName < out[j].FileName })
+
+	return out, nil
+}
+
+// compatibleModes reports whether profiles of the modes can be merged. Count and atomic mode profiles both count
+// the executions of each block, set mode profiles only record whether a block was executed.
+func compatibleModes(a, b string) bool {
+	return a == b || (a != modeSet && b != modeSet)
+}
+
+// mergeBlocks adds blocks to the profile. index maps the position of each block already in the profile to its index.
+func mergeBlocks(p *cover.Profile, index map[blockKey]int, blocks []cover.ProfileBlock) error {
+	for _, b := range blocks {
+		key := blockKey{startLine: b.StartLine, startCol: b.StartCol, endLine: b.EndLine, endCol: b.EndCol}
+
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(p.Blocks)
+			p.Blocks = append(p.Blocks, b)
+
+			continue
+		}
+
+		existing := &p.Blocks[i]
+		if existing.NumStmt != b.NumStmt {
+			return fmt.Errorf(
+				"inconsistent statement count for block %v:%v.%v,%v.%v",
+				p.FileName,
+				b.StartLine,
+				b.StartCol,
+				b.EndLine,
+				b.EndCol,
+			)
+		}
+
+		if p.Mode == modeSet {
+			if b.Count > 0 {
+				existing.Count = 1
+			}
+
+			continue
+		}
+
+		existing.Count += b.Count
+	}
+
+	return nil
+}
+
+// WriteProfiles writes the profiles in the coverage profile format produced by go test. The profiles are written with
+// the mode of the first one, so their modes must be compatible with it.
+func WriteProfiles(w io.Writer, profiles []*cover.Profile) error {
+	mode := modeSet
+	if len(profiles) > 0 {
+		mode = profiles[0].Mode
+	}
+
+	for _, p := range profiles {
+		if !compatibleModes(mode, p.Mode) {
+			return fmt.Errorf("cannot write profiles with modes %v and %v for %v", mode, p.Mode, p.FileName)
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "mode: %v\n", mode); err != nil {
+		return err
+	}
+
+	for _, p := range profiles {
+		for _, b := range p.Blocks {
+			_, err := fmt.Fprintf(
+				w,
+				"%v:%v.%v,%v.%v %v %v\n",
+				p.FileName,
+				b.StartLine,
+				b.StartCol,
+				b.EndLine,
+				b.EndCol,
+				b.NumStmt,
+				b.Count,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
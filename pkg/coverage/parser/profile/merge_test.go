@@ -0,0 +1,187 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+func Test_MergeProfiles(t *testing.T) {
+	type testcase struct {
+		mode         string
+		expectCounts []int
+	}
+
+	testCases := map[string]testcase{
+		"set mode blocks are covered if covered in any profile": {
+			mode:         "set",
+			expectCounts: []int{1, 0, 1, 1},
+		},
+		"count mode blocks are summed": {
+			mode:         "count",
+			expectCounts: []int{5, 0, 2, 1},
+		},
+		"atomic mode blocks are summed": {
+			mode:         "atomic",
+			expectCounts: []int{5, 0, 2, 1},
+		},
+	}
+
+	for desc := range testCases {
+		desc := desc
+		t.Run(desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := testCases[desc]
+
+			unit := []*cover.Profile{
+				{
+					FileName: "foo/a.go",
+					Mode:     tc.mode,
+					Blocks: []cover.ProfileBlock{
+						{StartLine: 3, StartCol: 2, EndLine: 3, EndCol: 10, NumStmt: 1, Count: 2},
+						{StartLine: 5, StartCol: 2, EndLine: 6, EndCol: 3, NumStmt: 2, Count: 0},
+						{StartLine: 8, StartCol: 2, EndLine: 8, EndCol: 10, NumStmt: 1, Count: 0},
+					},
+				},
+			}
+			e2e := []*cover.Profile{
+				{
+					FileName: "foo/b.go",
+					Mode:     tc.mode,
+					Blocks: []cover.ProfileBlock{
+						{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1},
+					},
+				},
+				{
+					FileName: "foo/a.go",
+					Mode:     tc.mode,
+					Blocks: []cover.ProfileBlock{
+						{StartLine: 8, StartCol: 2, EndLine: 8, EndCol: 10, NumStmt: 1, Count: 2},
+						{StartLine: 3, StartCol: 2, EndLine: 3, EndCol: 10, NumStmt: 1, Count: 3},
+					},
+				},
+			}
+
+			if tc.mode == "set" {
+				unit[0].Blocks[0].Count = 1
+				e2e[0].Blocks[0].Count = 1
+				e2e[1].Blocks[0].Count = 1
+				e2e[1].Blocks[1].Count = 1
+			}
+
+			merged, err := MergeProfiles(unit, e2e)
+			g.Expect(err).To(BeNil())
+			g.Expect(merged).To(HaveLen(2))
+			g.Expect(merged[0].FileName).To(Equal("foo/a.go"))
+			g.Expect(merged[1].FileName).To(Equal("foo/b.go"))
+
+			counts := []int{}
+			for _, b := range merged[0].Blocks {
+				counts = append(counts, b.Count)
+			}
+
+			counts = append(counts, merged[1].Blocks[0].Count)
+			g.Expect(counts).To(Equal(tc.expectCounts))
+		})
+	}
+}
+
+func Test_MergeProfiles_Errors(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	block := cover.ProfileBlock{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1}
+
+	_, err := MergeProfiles(
+		[]*cover.Profile{{FileName: "foo/a.go", Mode: "set", Blocks: []cover.ProfileBlock{block}}},
+		[]*cover.Profile{{FileName: "foo/a.go", Mode: "count", Blocks: []cover.ProfileBlock{block}}},
+	)
+	g.Expect(err).ToNot(BeNil())
+
+	_, err = MergeProfiles(
+		[]*cover.Profile{{FileName: "foo/a.go", Mode: "set", Blocks: []cover.ProfileBlock{block}}},
+		[]*cover.Profile{{FileName: "foo/b.go", Mode: "atomic", Blocks: []cover.ProfileBlock{block}}},
+	)
+	g.Expect(err).ToNot(BeNil())
+
+	err = WriteProfiles(bytes.NewBuffer(nil), []*cover.Profile{
+		{FileName: "foo/a.go", Mode: "count", Blocks: []cover.ProfileBlock{block}},
+		{FileName: "foo/b.go", Mode: "set", Blocks: []cover.ProfileBlock{block}},
+	})
+	g.Expect(err).ToNot(BeNil())
+
+	other := block
+	other.NumStmt = 2
+
+	_, err = MergeProfiles(
+		[]*cover.Profile{{FileName: "foo/a.go", Mode: "set", Blocks: []cover.ProfileBlock{block}}},
+		[]*cover.Profile{{FileName: "foo/a.go", Mode: "set", Blocks: []cover.ProfileBlock{other}}},
+	)
+	g.Expect(err).ToNot(BeNil())
+}
+
+func Test_MergeProfiles_CountAndAtomic(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	block := cover.ProfileBlock{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 2}
+
+	profiles, err := MergeProfiles(
+		[]*cover.Profile{{FileName: "foo/a.go", Mode: "atomic", Blocks: []cover.ProfileBlock{block}}},
+		[]*cover.Profile{
+			{FileName: "foo/a.go", Mode: "count", Blocks: []cover.ProfileBlock{block}},
+			{FileName: "foo/b.go", Mode: "count", Blocks: []cover.ProfileBlock{block}},
+		},
+	)
+	g.Expect(err).To(BeNil())
+	g.Expect(profiles).To(HaveLen(2))
+
+	for _, p := range profiles {
+		g.Expect(p.Mode).To(Equal("atomic"))
+	}
+
+	g.Expect(profiles[0].Blocks[0].Count).To(Equal(4))
+	g.Expect(profiles[1].Blocks[0].Count).To(Equal(2))
+}
+
+func Test_ParseProfiles(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir, err := ioutil.TempDir("", "profiles")
+	g.Expect(err).To(BeNil())
+
+	defer os.RemoveAll(dir)
+
+	unit := "mode: count\nfoo/a.go:3.2,3.10 1 2\nfoo/a.go:5.2,6.3 2 0\n"
+	e2e := "mode: count\nfoo/a.go:5.2,6.3 2 4\n"
+
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, "unit.cov"), []byte(unit), 0644)).To(Succeed())
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, "e2e.cov"), []byte(e2e), 0644)).To(Succeed())
+
+	profiles, err := ParseProfiles([]string{filepath.Join(dir, "*.cov")})
+	g.Expect(err).To(BeNil())
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteProfiles(buf, profiles)).To(Succeed())
+	g.Expect(buf.String()).To(Equal("mode: count\nfoo/a.go:3.2,3.10 1 2\nfoo/a.go:5.2,6.3 2 4\n"))
+
+	_, err = ParseProfiles([]string{filepath.Join(dir, "missing.cov")})
+	g.Expect(err).ToNot(BeNil())
+}
@@ -16,6 +16,7 @@ package profile
 
 import (
 	"go/token"
+	"sort"
 
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
 
@@ -27,8 +28,20 @@ type FunctionCoverage struct {
 	StatementCount int64
 	CoveredCount   int64
 	Name           string
-	Function       functions.Function
-	Profile        *cover.Profile
+	// Module is the path of the module the package of the function belongs to, empty outside of modules
+	Module string
+	// Untested is true when the package of the function has no test files
+	Untested bool
+	Function functions.Function
+	Profile  *cover.Profile
+	Blocks   []cover.ProfileBlock
+	// BranchCount is the number of branches of the decisions in the function and CoveredBranchCount the number of
+	// those which were taken
+	BranchCount        int64
+	CoveredBranchCount int64
+	// Closures holds the coverage of the function literals defined directly within the function. The counts and
+	// blocks of a function include those of its closures.
+	Closures []FunctionCoverage
 }
 
 type Parser struct {
@@ -40,40 +53,63 @@ type Parser struct {
 func (p Parser) RecordFunctionCoverage(functions []functions.Function) []FunctionCoverage {
 	out := make([]FunctionCoverage, 0, len(functions))
 
+	var index *blockIndex
+	if p.Profile != nil {
+		index = newBlockIndex(p.Profile.Blocks)
+	}
+
 	for _, function := range functions {
-		fc := FunctionCoverage{
-			Name:     function.Name,
-			Function: function,
-		}
+		out = append(out, p.recordFunction(function, index))
+	}
 
-		if p.Profile != nil {
-			fc = p.recordCoverageHits(fc, function)
-			fc.Profile = p.Profile
-		} else {
-			log.Debugf("profile is blank for function %v", function.Name)
-		}
+	return out
+}
+
+// recordFunction records the coverage of the function itself and then adds the coverage of each of its closures
+func (p Parser) recordFunction(function functions.Function, index *blockIndex) FunctionCoverage {
+	fc := FunctionCoverage{
+		Name:        function.Name,
+		Function:    function,
+		BranchCount: int64(len(function.Branches)),
+	}
 
-		if int(fc.StatementCount) != len(function.Statements) {
-			log.Debugf(
-				"function %v statement counts don't match Profile: %v AST: %v",
-				function.Name,
-				fc.StatementCount,
-				len(function.Statements),
-			)
-
-			if int(fc.StatementCount) == 0 && len(function.Statements) > 0 {
-				fc.StatementCount = int64(len(function.Statements))
-			}
+	if p.Profile != nil {
+		fc = recordCoverageHits(fc, function, index)
+		fc.CoveredBranchCount = recordBranchHits(function.Branches, index)
+		fc.Profile = p.Profile
+	} else {
+		log.Debugf("profile is blank for function %v", function.Name)
+	}
+
+	if int(fc.StatementCount) != len(function.Statements) {
+		log.Debugf(
+			"function %v statement counts don't match Profile: %v AST: %v",
+			function.Name,
+			fc.StatementCount,
+			len(function.Statements),
+		)
+
+		if int(fc.StatementCount) == 0 && len(function.Statements) > 0 {
+			fc.StatementCount = int64(len(function.Statements))
 		}
+	}
 
-		out = append(out, fc)
+	for _, closure := range function.Closures {
+		cc := p.recordFunction(closure, index)
+
+		fc.StatementCount += cc.StatementCount
+		fc.CoveredCount += cc.CoveredCount
+		fc.BranchCount += cc.BranchCount
+		fc.CoveredBranchCount += cc.CoveredBranchCount
+		fc.Blocks = append(fc.Blocks, cc.Blocks...)
+		fc.Closures = append(fc.Closures, cc)
 	}
 
-	return out
+	return fc
 }
 
-func (p Parser) recordCoverageHits(fc FunctionCoverage, function functions.Function) FunctionCoverage {
-	for _, block := range p.Profile.Blocks {
+func recordCoverageHits(fc FunctionCoverage, function functions.Function, index *blockIndex) FunctionCoverage {
+	for _, block := range index.blocksForLines(function.StartLine, function.EndLine) {
 		startLine := function.StartLine
 		startCol := function.StartCol
 		endLine := function.EndLine
@@ -84,13 +120,27 @@ func (p Parser) recordCoverageHits(fc FunctionCoverage, function functions.Funct
 			continue
 		}
 
-		if block.EndLine < startLine || (block.EndLine == startLine && block.EndCol <= startCol) {
-			// Block ends before the function statement starts
+		if block.StartLine < startLine || (block.StartLine == startLine && block.StartCol < startCol) {
+			// Block starts before the function, as a block of the enclosing function does for a closure
+			continue
+		}
+
+		if inClosure(function, block) {
+			// the block is recorded for the closure
 			continue
 		}
 
 		log.Debugf("function %v matched with block %v", function.Name, block)
+
+		ignored := ignoredStatementCount(function, block)
+		if ignored >= block.NumStmt {
+			log.Debugf("ignoring block %v of function %v", block, function.Name)
+			continue
+		}
+
+		block.NumStmt -= ignored
 		fc.StatementCount += int64(block.NumStmt)
+		fc.Blocks = append(fc.Blocks, block)
 
 		if block.Count > 0 {
 			fc.CoveredCount += int64(block.NumStmt)
@@ -99,3 +149,84 @@ func (p Parser) recordCoverageHits(fc FunctionCoverage, function functions.Funct
 
 	return fc
 }
+
+// inClosure reports whether the block lies within one of the closures of the function
+func inClosure(function functions.Function, block cover.ProfileBlock) bool {
+	for _, c := range function.Closures {
+		startsIn := block.StartLine > c.StartLine || (block.StartLine == c.StartLine && block.StartCol >= c.StartCol)
+		endsIn := block.EndLine < c.EndLine || (block.EndLine == c.EndLine && block.EndCol <= c.EndCol)
+
+		if startsIn && endsIn {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ignoredStatementCount returns the number of statements in the block which are ignored by directives. Blocks that lie
+// entirely within an ignored region are ignored as a whole.
+func ignoredStatementCount(function functions.Function, block cover.ProfileBlock) int {
+	for _, r := range function.Ignored {
+		if r.Contains(block.StartLine, block.StartCol, block.EndLine, block.EndCol) {
+			return block.NumStmt
+		}
+	}
+
+	count := 0
+
+	for _, s := range function.IgnoredStatements {
+		if positionInBlock(block, int(s.StartLine), int(s.StartCol)) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// positionInBlock reports whether the position lies within the block, which excludes its end
+func positionInBlock(block cover.ProfileBlock, line, col int) bool {
+	return (line > block.StartLine || (line == block.StartLine && col >= block.StartCol)) &&
+		(line < block.EndLine || (line == block.EndLine && col < block.EndCol))
+}
+
+// blockIndex finds the profile blocks on a range of lines without scanning every block of the profile
+type blockIndex struct {
+	// blocks are sorted by start position
+	blocks []cover.ProfileBlock
+	// maxEndLine holds the greatest end line of blocks up to and including each index
+	maxEndLine []int
+}
+
+func newBlockIndex(blocks []cover.ProfileBlock) *blockIndex {
+	sorted := make([]cover.ProfileBlock, len(blocks))
+	copy(sorted, blocks)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		bi, bj := sorted[i], sorted[j]
+		return bi.StartLine < bj.StartLine || (bi.StartLine == bj.StartLine && bi.StartCol < bj.StartCol)
+	})
+
+	maxEndLine := make([]int, len(sorted))
+
+	for i, b := range sorted {
+		maxEndLine[i] = b.EndLine
+		if i > 0 && maxEndLine[i-1] > b.EndLine {
+			maxEndLine[i] = maxEndLine[i-1]
+		}
+	}
+
+	return &blockIndex{blocks: sorted, maxEndLine: maxEndLine}
+}
+
+// blocksForLines returns the blocks which may overlap the lines from startLine to endLine, in order of position
+func (idx *blockIndex) blocksForLines(startLine, endLine int) []cover.ProfileBlock {
+	first := sort.Search(len(idx.blocks), func(i int) bool { return idx.maxEndLine[i] >= startLine })
+	last := sort.Search(len(idx.blocks), func(i int) bool { return idx.blocks[i].StartLine > endLine })
+
+	if first >= last {
+		return nil
+	}
+
+	return idx.blocks[first:last]
+}
@@ -15,12 +15,15 @@
 package profile
 
 import (
+	"fmt"
+	"go/parser"
 	"go/token"
 	"io/ioutil"
 	"testing"
 
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser"
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
 	. "github.com/onsi/gomega"
 	"golang.org/x/tools/cover"
 )
@@ -255,6 +258,7 @@ func Test_Parser_RecordFunctionCoverage(t *testing.T) {
 						EndCol:    1,
 					},
 					Profile: profile,
+					Blocks:  profile.Blocks,
 				},
 			},
 		},
@@ -272,3 +276,163 @@ func Test_Parser_RecordFunctionCoverage(t *testing.T) {
 		})
 	}
 }
+
+func Test_Parser_RecordFunctionCoverage_Ignored(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	function := functions.Function{
+		Name:      "Check",
+		StartLine: 1,
+		StartCol:  1,
+		EndLine:   10,
+		EndCol:    2,
+		Ignored: []functions.IgnoredRegion{
+			{StartLine: 3, StartCol: 3, EndLine: 3, EndCol: 23},
+			{StartLine: 6, StartCol: 2, EndLine: 8, EndCol: 3},
+		},
+		IgnoredStatements: []statements.Statement{
+			{StartLine: 3, StartCol: 3, EndLine: 3, EndCol: 23},
+			{StartLine: 6, StartCol: 2, EndLine: 8, EndCol: 3},
+			{StartLine: 7, StartCol: 3, EndLine: 7, EndCol: 13},
+		},
+	}
+
+	p := Parser{
+		Profile: &cover.Profile{
+			Blocks: []cover.ProfileBlock{
+				// block with a statement which is not ignored
+				{StartLine: 2, StartCol: 2, EndLine: 2, EndCol: 11, NumStmt: 1, Count: 1},
+				// block with a statement which is not ignored followed by an ignored one
+				{StartLine: 2, StartCol: 12, EndLine: 4, EndCol: 3, NumStmt: 2, Count: 0},
+				// blocks within an ignored if statement
+				{StartLine: 6, StartCol: 2, EndLine: 6, EndCol: 13, NumStmt: 1, Count: 1},
+				{StartLine: 6, StartCol: 14, EndLine: 8, EndCol: 3, NumStmt: 1, Count: 0},
+				{StartLine: 9, StartCol: 2, EndLine: 9, EndCol: 10, NumStmt: 1, Count: 1},
+			},
+		},
+	}
+
+	fcs := p.RecordFunctionCoverage([]functions.Function{function})
+	g.Expect(fcs).To(HaveLen(1))
+	g.Expect(fcs[0].StatementCount).To(Equal(int64(3)))
+	g.Expect(fcs[0].CoveredCount).To(Equal(int64(2)))
+	g.Expect(fcs[0].Blocks).To(HaveLen(3))
+	g.Expect(fcs[0].Blocks[1].NumStmt).To(Equal(1))
+}
+
+func Test_Parser_RecordFunctionCoverage_Closures(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	src := `package foo
+
+func Serve(xs []int) {
+	handle := func(x int) {
+		go func() {
+			println(x)
+		}()
+	}
+	for _, x := range xs {
+		handle(x)
+	}
+	defer func() { recover() }()
+}
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", []byte(src), parser.ParseComments)
+	g.Expect(err).To(BeNil())
+
+	funcs, err := functions.CollectFunctions(f, fset, "foo.go")
+	g.Expect(err).To(BeNil())
+
+	// blocks as written by go test -coverprofile for the source above
+	p := Parser{
+		Profile: &cover.Profile{
+			Blocks: []cover.ProfileBlock{
+				{StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 24, NumStmt: 1, Count: 1},
+				{StartLine: 5, StartCol: 3, EndLine: 5, EndCol: 13, NumStmt: 1, Count: 0},
+				{StartLine: 6, StartCol: 4, EndLine: 7, EndCol: 1, NumStmt: 1, Count: 0},
+				{StartLine: 9, StartCol: 2, EndLine: 9, EndCol: 23, NumStmt: 1, Count: 1},
+				{StartLine: 10, StartCol: 3, EndLine: 11, EndCol: 1, NumStmt: 1, Count: 0},
+				{StartLine: 12, StartCol: 2, EndLine: 12, EndCol: 15, NumStmt: 1, Count: 1},
+				{StartLine: 12, StartCol: 17, EndLine: 12, EndCol: 28, NumStmt: 1, Count: 1},
+			},
+		},
+	}
+
+	fcs := p.RecordFunctionCoverage(funcs)
+	g.Expect(fcs).To(HaveLen(1))
+
+	serve := fcs[0]
+	g.Expect(serve.StatementCount).To(Equal(int64(7)))
+	g.Expect(serve.CoveredCount).To(Equal(int64(4)))
+	g.Expect(serve.Blocks).To(HaveLen(7))
+	g.Expect(serve.Closures).To(HaveLen(2))
+
+	handle := serve.Closures[0]
+	g.Expect(handle.Name).To(Equal("Serve.func1"))
+	g.Expect(handle.StatementCount).To(Equal(int64(2)))
+	g.Expect(handle.CoveredCount).To(Equal(int64(0)))
+	g.Expect(handle.Closures).To(HaveLen(1))
+	g.Expect(handle.Closures[0].StatementCount).To(Equal(int64(1)))
+
+	deferred := serve.Closures[1]
+	g.Expect(deferred.Name).To(Equal("Serve.func2"))
+	g.Expect(deferred.StatementCount).To(Equal(int64(1)))
+	g.Expect(deferred.CoveredCount).To(Equal(int64(1)))
+}
+
+func Test_blockIndex_blocksForLines(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	idx := newBlockIndex([]cover.ProfileBlock{
+		{StartLine: 20, StartCol: 2, EndLine: 22, EndCol: 3},
+		{StartLine: 3, StartCol: 2, EndLine: 10, EndCol: 3},
+		{StartLine: 4, StartCol: 5, EndLine: 5, EndCol: 3},
+		{StartLine: 12, StartCol: 2, EndLine: 12, EndCol: 10},
+	})
+
+	startLines := func(blocks []cover.ProfileBlock) []int {
+		out := []int{}
+		for _, b := range blocks {
+			out = append(out, b.StartLine)
+		}
+
+		return out
+	}
+
+	g.Expect(startLines(idx.blocksForLines(1, 2))).To(BeEmpty())
+	g.Expect(startLines(idx.blocksForLines(6, 11))).To(Equal([]int{3, 4}))
+	g.Expect(startLines(idx.blocksForLines(11, 12))).To(Equal([]int{12}))
+	g.Expect(startLines(idx.blocksForLines(13, 19))).To(BeEmpty())
+	g.Expect(startLines(idx.blocksForLines(1, 30))).To(Equal([]int{3, 4, 12, 20}))
+}
+
+func Benchmark_Parser_RecordFunctionCoverage(b *testing.B) {
+	for _, funcCount := range []int{100, 1000, 5000} {
+		funcCount := funcCount
+
+		b.Run(fmt.Sprintf("funcs=%d", funcCount), func(b *testing.B) {
+			fns := make([]functions.Function, 0, funcCount)
+			prof := &cover.Profile{Mode: "set"}
+
+			for i := 0; i < funcCount; i++ {
+				line := 1 + i*8
+				fns = append(fns, functions.Function{StartLine: line, StartCol: 1, EndLine: line + 6, EndCol: 2})
+				prof.Blocks = append(prof.Blocks,
+					cover.ProfileBlock{StartLine: line, StartCol: 24, EndLine: line + 1, EndCol: 12, NumStmt: 1, Count: 1},
+					cover.ProfileBlock{StartLine: line + 1, StartCol: 12, EndLine: line + 3, EndCol: 3, NumStmt: 1},
+					cover.ProfileBlock{StartLine: line + 5, StartCol: 2, EndLine: line + 5, EndCol: 11, NumStmt: 1, Count: 1},
+				)
+			}
+
+			p := Parser{Profile: prof}
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				p.RecordFunctionCoverage(fns)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"sort"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/statements"
+	"golang.org/x/tools/cover"
+)
+
+// MisalignedBlocks returns the blocks within the functions which do not contain the start of any statement of the
+// functions. Blocks of a profile generated from the same source always begin at or before one of their statements, so
+// misaligned blocks mean the source changed after the profile was generated. Blocks outside of every function are
+// not checked.
+func MisalignedBlocks(funcs []functions.Function, blocks []cover.ProfileBlock) []cover.ProfileBlock {
+	var stmts []statements.Statement
+	for _, f := range funcs {
+		stmts = appendStatements(stmts, f)
+	}
+
+	sort.Slice(stmts, func(i, j int) bool {
+		return stmts[i].StartLine < stmts[j].StartLine ||
+			(stmts[i].StartLine == stmts[j].StartLine && stmts[i].StartCol < stmts[j].StartCol)
+	})
+
+	out := make([]cover.ProfileBlock, 0)
+
+	for _, block := range blocks {
+		if block.NumStmt == 0 || !withinFunction(funcs, block) {
+			continue
+		}
+
+		if !containsStatement(block, stmts) {
+			out = append(out, block)
+		}
+	}
+
+	return out
+}
+
+// appendStatements appends the statements of the function and its closures, including ignored statements
+func appendStatements(stmts []statements.Statement, f functions.Function) []statements.Statement {
+	stmts = append(stmts, f.Statements...)
+	stmts = append(stmts, f.IgnoredStatements...)
+
+	for _, c := range f.Closures {
+		stmts = appendStatements(stmts, c)
+	}
+
+	return stmts
+}
+
+func withinFunction(funcs []functions.Function, block cover.ProfileBlock) bool {
+	for _, f := range funcs {
+		startsIn := block.StartLine > f.StartLine || (block.StartLine == f.StartLine && block.StartCol >= f.StartCol)
+		startsBeforeEnd := block.StartLine < f.EndLine || (block.StartLine == f.EndLine && block.StartCol < f.EndCol)
+
+		if startsIn && startsBeforeEnd {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsStatement reports whether the start of any of the sorted statements lies within the block
+func containsStatement(block cover.ProfileBlock, stmts []statements.Statement) bool {
+	i := sort.Search(len(stmts), func(i int) bool {
+		line, col := int(stmts[i].StartLine), int(stmts[i].StartCol)
+		return line > block.StartLine || (line == block.StartLine && col >= block.StartCol)
+	})
+
+	return i < len(stmts) && positionInBlock(block, int(stmts[i].StartLine), int(stmts[i].StartCol))
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package profile
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+func Test_MisalignedBlocks(t *testing.T) {
+	src := `package foo
+
+func Serve(xs []int) {
+	handle := func(x int) {
+		println(x)
+	}
+	for _, x := range xs {
+		handle(x)
+	}
+}
+`
+
+	type testcase struct {
+		blocks   []cover.ProfileBlock
+		expected int
+	}
+
+	testCases := map[string]testcase{
+		"blocks from the same source": {
+			blocks: []cover.ProfileBlock{
+				{StartLine: 4, StartCol: 2, EndLine: 4, EndCol: 24, NumStmt: 1, Count: 1},
+				{StartLine: 5, StartCol: 3, EndLine: 5, EndCol: 13, NumStmt: 1, Count: 0},
+				{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 23, NumStmt: 1, Count: 1},
+				{StartLine: 8, StartCol: 3, EndLine: 9, EndCol: 1, NumStmt: 1, Count: 0},
+			},
+		},
+		"blocks which start at braces": {
+			blocks: []cover.ProfileBlock{
+				{StartLine: 3, StartCol: 22, EndLine: 4, EndCol: 24, NumStmt: 1, Count: 1},
+				{StartLine: 4, StartCol: 24, EndLine: 6, EndCol: 3, NumStmt: 1, Count: 0},
+				{StartLine: 7, StartCol: 23, EndLine: 9, EndCol: 3, NumStmt: 1, Count: 0},
+			},
+		},
+		"blocks from source with two more lines": {
+			blocks: []cover.ProfileBlock{
+				{StartLine: 6, StartCol: 2, EndLine: 6, EndCol: 24, NumStmt: 1, Count: 1},
+				{StartLine: 7, StartCol: 3, EndLine: 7, EndCol: 13, NumStmt: 1, Count: 0},
+				{StartLine: 9, StartCol: 2, EndLine: 9, EndCol: 23, NumStmt: 1, Count: 1},
+				{StartLine: 10, StartCol: 3, EndLine: 11, EndCol: 1, NumStmt: 1, Count: 0},
+			},
+			expected: 3,
+		},
+		"blocks outside of functions and without statements": {
+			blocks: []cover.ProfileBlock{
+				{StartLine: 1, StartCol: 1, EndLine: 1, EndCol: 5, NumStmt: 1, Count: 0},
+				{StartLine: 6, StartCol: 1, EndLine: 6, EndCol: 2, NumStmt: 0, Count: 0},
+			},
+		},
+	}
+
+	for desc := range testCases {
+		desc := desc
+		t.Run(desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := testCases[desc]
+
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "foo.go", []byte(src), parser.ParseComments)
+			g.Expect(err).To(BeNil())
+
+			funcs, err := functions.CollectFunctions(f, fset, "foo.go")
+			g.Expect(err).To(BeNil())
+
+			g.Expect(MisalignedBlocks(funcs, tc.blocks)).To(HaveLen(tc.expected))
+		})
+	}
+}
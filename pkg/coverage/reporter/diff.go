@@ -0,0 +1,91 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/diff"
+)
+
+type DiffReport struct {
+	diff.Coverage
+	Base                  string  `json:"base"`
+	MinCoveragePercentage float64 `json:"min_coverage_percentage"`
+	Pass                  bool    `json:"pass"`
+}
+
+func NewDiffReport(cov diff.Coverage, base string, minCov float64) DiffReport {
+	return DiffReport{
+		Coverage:              cov,
+		Base:                  base,
+		MinCoveragePercentage: minCov,
+		Pass:                  minCov <= cov.CoveragePercent,
+	}
+}
+
+func WriteDiffJSON(w io.Writer, r DiffReport) error {
+	return writeJSON(w, r)
+}
+
+// VerifyDiffCoverage prints the coverage of changed statements and the changed lines which are not covered
+func (v Verifier) VerifyDiffCoverage(r DiffReport) bool {
+	v.Out.Printf(
+		"changes since %v\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v\n",
+		r.Base,
+		r.CoveragePercent,
+		r.MinCoveragePercentage,
+		r.ExecutedCount,
+		r.StatementCount,
+	)
+
+	if len(r.Uncovered) > 0 {
+		v.Out.Printf("\nuncovered changed lines\n")
+	}
+
+	for _, u := range r.Uncovered {
+		v.Out.Printf(
+			"%v\tfunc %v\tlines %v\n",
+			filepath.Join(u.Package, filepath.Base(u.SrcPath)),
+			u.Function,
+			formatLines(u.Lines),
+		)
+	}
+
+	return r.Pass
+}
+
+// formatLines collapses sorted line numbers into ranges e.g. 1-3,7
+func formatLines(lines []int) string {
+	parts := make([]string, 0)
+
+	for i := 0; i < len(lines); i++ {
+		start := lines[i]
+		for i+1 < len(lines) && lines[i+1] == lines[i]+1 {
+			i++
+		}
+
+		if lines[i] == start {
+			parts = append(parts, fmt.Sprintf("%d", start))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", start, lines[i]))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/mocks/coverage/mock_reporter"
+	"github.com/cvgw/gocheckcov/pkg/coverage/diff"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Verifier_VerifyDiffCoverage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockLogger := mock_reporter.NewMocklogger(ctrl)
+	mockLogger.EXPECT().Printf(gomock.Any(), gomock.Any()).Times(1)
+	mockLogger.EXPECT().Printf("\nuncovered changed lines\n").Times(1)
+	mockLogger.EXPECT().Printf(gomock.Any(), "foo/bar/meow.go", "Meow", "5-7,9").Times(1)
+
+	cov := diff.Coverage{
+		StatementCount:  4,
+		ExecutedCount:   1,
+		CoveragePercent: 25,
+		Uncovered: []diff.UncoveredFunction{
+			{Package: "foo/bar", SrcPath: "/src/foo/bar/meow.go", Function: "Meow", Lines: []int{5, 6, 7, 9}},
+		},
+	}
+
+	v := Verifier{Out: mockLogger}
+	g.Expect(v.VerifyDiffCoverage(NewDiffReport(cov, "origin/main", 50))).To(BeFalse())
+}
+
+func Test_formatLines(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(formatLines(nil)).To(Equal(""))
+	g.Expect(formatLines([]int{3})).To(Equal("3"))
+	g.Expect(formatLines([]int{1, 2, 3, 7, 9, 10})).To(Equal("1-3,7,9-10"))
+}
@@ -0,0 +1,264 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/cover"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+)
+
+const htmlStyle = `
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.2em 0.8em; text-align: left; border-bottom: 1px solid #ddd; }
+.pass { color: #2a7d2a; }
+.fail { color: #b52a2a; }
+.src { font-family: monospace; white-space: pre; }
+.lines { color: #999; text-align: right; padding-right: 1em; user-select: none; }
+.lines a { color: #999; text-decoration: none; }
+.cov-covered { background: #d9f2d9; }
+.cov-uncovered { background: #f6d4d4; }
+`
+
+const htmlIndexTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>gocheckcov report</title><link rel="stylesheet" href="style.css"></head>
+<body>
+<h1>Coverage report</h1>
+<p class="{{if .Report.Pass}}pass{{else}}fail{{end}}">{{if .Report.Pass}}all packages passed{{else}}packages failed to meet minimum coverage{{end}}</p>
+<p class="{{if .Report.Total.Pass}}pass{{else}}fail{{end}}">total coverage {{.Report.Total.CoveragePercent}}% minimum {{.Report.Total.MinCoveragePercentage}}% statements {{.Report.Total.ExecutedCount}}/{{.Report.Total.StatementCount}}</p>
+{{range .Report.Modules}}<p>module {{.Module}} coverage {{.CoveragePercent}}% statements {{.ExecutedCount}}/{{.StatementCount}}</p>
+{{end}}<table>
+<tr><th>package</th><th>coverage</th><th>minimum</th><th>statements</th><th>branches</th><th>status</th><th>tests</th></tr>
+{{range .Packages}}<tr>
+<td><a href="{{.Page}}">{{.Report.Path}}</a></td>
+<td>{{.Report.CoveragePercent}}%</td>
+<td>{{.Report.MinCoveragePercentage}}%</td>
+<td>{{.Report.ExecutedCount}}/{{.Report.StatementCount}}</td>
+<td>{{.Report.CoveredBranchCount}}/{{.Report.BranchCount}}</td>
+<td class="{{if .Report.Pass}}pass{{else}}fail{{end}}">{{if .Report.Pass}}pass{{else}}fail{{end}}</td>
+<td>{{if .Report.Untested}}no tests{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`
+
+const htmlPackageTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Report.Path}}</title><link rel="stylesheet" href="style.css"></head>
+<body>
+<p><a href="index.html">index</a></p>
+<h1>{{.Report.Path}}</h1>
+<p class="{{if .Report.Pass}}pass{{else}}fail{{end}}">coverage {{.Report.CoveragePercent}}% minimum {{.Report.MinCoveragePercentage}}% statements {{.Report.ExecutedCount}}/{{.Report.StatementCount}} branch coverage {{.Report.BranchCoveragePercent}}% minimum {{.Report.MinBranchCoveragePercentage}}% branches {{.Report.CoveredBranchCount}}/{{.Report.BranchCount}}</p>
+<table>
+<tr><th>function</th><th>file</th><th>coverage</th><th>statements</th><th>branches</th></tr>
+{{range .Functions}}<tr>
+<td><a href="{{.Link}}">{{.Report.Name}}</a></td>
+<td>{{.File}}:{{.Report.StartLine}}</td>
+<td>{{.Report.CoveragePercent}}%</td>
+<td>{{.Report.ExecutedCount}}/{{.Report.StatementCount}}</td>
+<td>{{.Report.CoveredBranchCount}}/{{.Report.BranchCount}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`
+
+const htmlFileTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Path}}</title><link rel="stylesheet" href="style.css"></head>
+<body>
+<p><a href="index.html">index</a> / <a href="{{.PackagePage}}">{{.Package}}</a></p>
+<h1>{{.Path}}</h1>
+<table>
+<tr>
+<td class="src lines">{{range .Lines}}<a id="L{{.}}" href="#L{{.}}">{{.}}</a>
+{{end}}</td>
+<td class="src">{{.Source}}</td>
+</tr>
+</table>
+</body>
+</html>
+`
+
+var (
+	htmlIndex   = template.Must(template.New("index").Parse(htmlIndexTemplate))
+	htmlPackage = template.Must(template.New("package").Parse(htmlPackageTemplate))
+	htmlFile    = template.Must(template.New("file").Parse(htmlFileTemplate))
+)
+
+type htmlPackagePage struct {
+	Page      string
+	Report    PackageReport
+	Functions []htmlFunctionRow
+}
+
+type htmlFunctionRow struct {
+	Report FunctionReport
+	File   string
+	Link   string
+}
+
+type htmlFilePage struct {
+	Path        string
+	Package     string
+	PackagePage string
+	Lines       []int
+	Source      template.HTML
+}
+
+// WriteHTML writes a static html site for the report into dir. The site contains an index of packages, a page of
+// functions for each package, and the annotated source of each file.
+func WriteHTML(dir string, r Report, packageToFunctions map[string][]profile.FunctionCoverage) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "style.css"), []byte(htmlStyle), 0644); err != nil {
+		return err
+	}
+
+	filePages := make(map[string]string)
+	pkgPages := make([]htmlPackagePage, 0, len(r.Packages))
+
+	for i, pr := range r.Packages {
+		page := htmlPackagePage{Page: fmt.Sprintf("pkg_%d.html", i), Report: pr}
+
+		srcFiles := profilesForFiles(packageToFunctions[pr.Path])
+		for _, path := range sortedFiles(srcFiles) {
+			filePage := fmt.Sprintf("file_%d.html", len(filePages))
+			filePages[path] = filePage
+
+			if err := writeHTMLFile(dir, filePage, path, srcFiles[path], page); err != nil {
+				return err
+			}
+		}
+
+		for _, fr := range pr.Functions {
+			page.Functions = append(page.Functions, htmlFunctionRow{
+				Report: fr,
+				File:   filepath.Base(fr.SrcPath),
+				Link:   fmt.Sprintf("%v#L%d", filePages[fr.SrcPath], fr.StartLine),
+			})
+		}
+
+		if err := executeTemplate(htmlPackage, filepath.Join(dir, page.Page), page); err != nil {
+			return err
+		}
+
+		pkgPages = append(pkgPages, page)
+	}
+
+	index := struct {
+		Report   Report
+		Packages []htmlPackagePage
+	}{Report: r, Packages: pkgPages}
+
+	return executeTemplate(htmlIndex, filepath.Join(dir, "index.html"), index)
+}
+
+func writeHTMLFile(dir, page, path string, prof *cover.Profile, pkgPage htmlPackagePage) error {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	boundaries := []cover.Boundary{}
+	if prof != nil {
+		boundaries = prof.Boundaries(src)
+	}
+
+	classes := map[coverageState]string{
+		stateCovered:   "cov-covered",
+		stateUncovered: "cov-uncovered",
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	for _, seg := range srcSegments(src, boundaries, 0, len(src)) {
+		text := template.HTMLEscapeString(seg.Text)
+		if class, ok := classes[seg.State]; ok {
+			text = fmt.Sprintf(`<span class="%v">%v</span>`, class, text)
+		}
+
+		buf.WriteString(text)
+	}
+
+	lineCount := strings.Count(string(src), "\n")
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		lineCount++
+	}
+
+	lines := make([]int, 0, lineCount)
+	for i := 1; i <= lineCount; i++ {
+		lines = append(lines, i)
+	}
+
+	fp := htmlFilePage{
+		Path:        path,
+		Package:     pkgPage.Report.Path,
+		PackagePage: pkgPage.Page,
+		Lines:       lines,
+		// each segment of the source has already been escaped
+		Source: template.HTML(buf.String()),
+	}
+
+	return executeTemplate(htmlFile, filepath.Join(dir, page), fp)
+}
+
+func executeTemplate(t *template.Template, path string, data interface{}) error {
+	buf := bytes.NewBuffer(nil)
+	if err := t.Execute(buf, data); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// profilesForFiles maps each source file of the functions to its profile, if there is one
+func profilesForFiles(functions []profile.FunctionCoverage) map[string]*cover.Profile {
+	out := make(map[string]*cover.Profile)
+
+	for _, fc := range functions {
+		if prof, ok := out[fc.Function.SrcPath]; !ok || prof == nil {
+			out[fc.Function.SrcPath] = fc.Profile
+		}
+	}
+
+	return out
+}
+
+func sortedFiles(files map[string]*cover.Profile) []string {
+	keys := make([]string, 0, len(files))
+
+	for path := range files {
+		keys = append(keys, path)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
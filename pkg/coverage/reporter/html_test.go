@@ -0,0 +1,98 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+func Test_WriteHTML(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Errorf("could not create temp dir %v", err)
+		t.FailNow()
+	}
+
+	srcContent := `package foo
+
+func Meow(x, y int) bool {
+	if x > y {
+		return true
+	}
+	return false
+}
+`
+	srcPath := filepath.Join(dir, "src.go")
+
+	if err := ioutil.WriteFile(srcPath, []byte(srcContent), 0644); err != nil {
+		t.Errorf("could not write to temp file %v", err)
+		t.FailNow()
+	}
+
+	prof := &cover.Profile{
+		Mode: "set",
+		Blocks: []cover.ProfileBlock{
+			{StartLine: 3, StartCol: 26, EndLine: 4, EndCol: 11, NumStmt: 1, Count: 1},
+			{StartLine: 4, StartCol: 11, EndLine: 6, EndCol: 3, NumStmt: 1, Count: 0},
+			{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 14, NumStmt: 1, Count: 1},
+		},
+	}
+
+	packageToFunctions := map[string][]profile.FunctionCoverage{
+		"foo": []profile.FunctionCoverage{
+			{
+				Name:           "Meow",
+				StatementCount: 3,
+				CoveredCount:   2,
+				Function:       functions.Function{Name: "Meow", SrcPath: srcPath, StartLine: 3},
+				Profile:        prof,
+				Untested:       true,
+			},
+		},
+	}
+
+	r, err := (Verifier{MinCov: 80}).Report(packageToFunctions, nil)
+	g.Expect(err).To(BeNil())
+
+	outDir := filepath.Join(dir, "html")
+	g.Expect(WriteHTML(outDir, r, packageToFunctions)).To(Succeed())
+
+	index, err := ioutil.ReadFile(filepath.Join(outDir, "index.html"))
+	g.Expect(err).To(BeNil())
+	g.Expect(string(index)).To(ContainSubstring(`<a href="pkg_0.html">foo</a>`))
+	g.Expect(string(index)).To(ContainSubstring(`<td class="fail">fail</td>`))
+	g.Expect(string(index)).To(ContainSubstring(`<td>no tests</td>`))
+
+	pkgPage, err := ioutil.ReadFile(filepath.Join(outDir, "pkg_0.html"))
+	g.Expect(err).To(BeNil())
+	g.Expect(string(pkgPage)).To(ContainSubstring(`<a href="file_0.html#L3">Meow</a>`))
+
+	filePage, err := ioutil.ReadFile(filepath.Join(outDir, "file_0.html"))
+	g.Expect(err).To(BeNil())
+	g.Expect(string(filePage)).To(ContainSubstring(`<span class="cov-uncovered">{
+		return true
+	}</span>`))
+	g.Expect(string(filePage)).To(ContainSubstring(`<span class="cov-covered">return false</span>`))
+	g.Expect(string(filePage)).To(ContainSubstring("x &gt; y"))
+}
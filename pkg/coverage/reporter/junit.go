@@ -0,0 +1,298 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const junitSuiteName = "gocheckcov"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML with one testcase per package, one per function with a function rule
+// or a CRAP score above the maximum, one for the total coverage when it has a minimum, and one per tree rule
+func WriteJUnit(w io.Writer, r Report) error {
+	suite := junitTestSuite{
+		Name:      junitSuiteName,
+		TestCases: make([]junitTestCase, 0, len(r.Packages)),
+	}
+
+	for _, pkg := range r.Packages {
+		tc := junitTestCase{
+			Name:      pkg.Path,
+			ClassName: junitSuiteName,
+			SystemOut: fmt.Sprintf(
+				"coverage %v%% minimum %v%% statements %v/%v branch coverage %v%% minimum %v%% branches %v/%v",
+				pkg.CoveragePercent,
+				pkg.MinCoveragePercentage,
+				pkg.ExecutedCount,
+				pkg.StatementCount,
+				pkg.BranchCoveragePercent,
+				pkg.MinBranchCoveragePercentage,
+				pkg.CoveredBranchCount,
+				pkg.BranchCount,
+			),
+		}
+
+		if pkg.Untested {
+			tc.SystemOut = fmt.Sprintf("no tests %v", tc.SystemOut)
+		}
+
+		if !pkg.Pass {
+			msg := packageFailure(pkg)
+			tc.Failure = &junitFailure{
+				Message: msg,
+				Type:    "coverage",
+				Content: msg,
+			}
+			suite.Failures++
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, tc)
+
+		for _, fn := range pkg.Functions {
+			if fn.Rule == "" && fn.Pass {
+				continue
+			}
+
+			ftc := newJUnitFunctionTestCase(pkg.Path, fn)
+			if ftc.Failure != nil {
+				suite.Failures++
+			}
+
+			suite.Tests++
+			suite.TestCases = append(suite.TestCases, ftc)
+		}
+	}
+
+	if r.Total.MinCoveragePercentage > 0 {
+		tc := newJUnitTotalTestCase(r.Total)
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	for _, tr := range r.Trees {
+		tc := newJUnitTreeTestCase(tr)
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	suites := junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+func newJUnitTotalTestCase(t TotalReport) junitTestCase {
+	tc := junitTestCase{
+		Name:      "total",
+		ClassName: junitSuiteName,
+		SystemOut: fmt.Sprintf(
+			"coverage %v%% minimum %v%% statements %v/%v",
+			t.CoveragePercent,
+			t.MinCoveragePercentage,
+			t.ExecutedCount,
+			t.StatementCount,
+		),
+	}
+
+	if !t.Pass {
+		msg := fmt.Sprintf(
+			"total coverage %v%% did not meet minimum %v%%",
+			t.CoveragePercent,
+			t.MinCoveragePercentage,
+		)
+		tc.Failure = &junitFailure{
+			Message: msg,
+			Type:    "coverage",
+			Content: msg,
+		}
+	}
+
+	return tc
+}
+
+func newJUnitTreeTestCase(tr TreeReport) junitTestCase {
+	tc := junitTestCase{
+		Name:      fmt.Sprintf("%v/...", strings.TrimSuffix(tr.Path, "/...")),
+		ClassName: junitSuiteName,
+		SystemOut: fmt.Sprintf(
+			"coverage %v%% minimum %v%% statements %v/%v",
+			tr.CoveragePercent,
+			tr.MinCoveragePercentage,
+			tr.ExecutedCount,
+			tr.StatementCount,
+		),
+	}
+
+	if !tr.Pass {
+		msg := fmt.Sprintf(
+			"coverage %v%% for tree %v did not meet minimum %v%%",
+			tr.CoveragePercent,
+			tr.Path,
+			tr.MinCoveragePercentage,
+		)
+		if tr.Missing {
+			msg = fmt.Sprintf("tree %v has no packages", tr.Path)
+		}
+
+		tc.Failure = &junitFailure{
+			Message: msg,
+			Type:    "coverage",
+			Content: msg,
+		}
+	}
+
+	return tc
+}
+
+// packageFailure describes why the package failed, preferring statement coverage over branch coverage over a lack of
+// tests
+func packageFailure(pkg PackageReport) string {
+	switch {
+	case pkg.MinCoveragePercentage > pkg.CoveragePercent:
+		return fmt.Sprintf(
+			"coverage %v%% for package %v did not meet minimum %v%%",
+			pkg.CoveragePercent,
+			pkg.Path,
+			pkg.MinCoveragePercentage,
+		)
+	case pkg.MinBranchCoveragePercentage > pkg.BranchCoveragePercent:
+		return fmt.Sprintf(
+			"branch coverage %v%% for package %v did not meet minimum %v%%",
+			pkg.BranchCoveragePercent,
+			pkg.Path,
+			pkg.MinBranchCoveragePercentage,
+		)
+	default:
+		return fmt.Sprintf("package %v has no tests", pkg.Path)
+	}
+}
+
+func newJUnitFunctionTestCase(pkg string, fn FunctionReport) junitTestCase {
+	tc := junitTestCase{
+		Name:      fmt.Sprintf("%v.%v", pkg, fn.Name),
+		ClassName: junitSuiteName,
+		File:      fn.SrcPath,
+		Line:      fn.StartLine,
+		SystemOut: fmt.Sprintf(
+			"coverage %v%% minimum %v%% statements %v/%v branches %v/%v",
+			fn.CoveragePercent,
+			fn.MinCoveragePercentage,
+			fn.ExecutedCount,
+			fn.StatementCount,
+			fn.CoveredBranchCount,
+			fn.BranchCount,
+		),
+	}
+
+	if !fn.Pass {
+		msg := functionFailure(pkg, fn)
+		tc.Failure = &junitFailure{
+			Message: msg,
+			Type:    "coverage",
+			Content: msg,
+		}
+	}
+
+	return tc
+}
+
+// functionFailure describes why the function failed, preferring statement coverage over branch coverage over its
+// CRAP score
+func functionFailure(pkg string, fn FunctionReport) string {
+	switch {
+	case fn.MinCoveragePercentage > fn.CoveragePercent:
+		return fmt.Sprintf(
+			"coverage %v%% for function %v in package %v did not meet minimum %v%%",
+			fn.CoveragePercent,
+			fn.Name,
+			pkg,
+			fn.MinCoveragePercentage,
+		)
+	case fn.MinBranchCoveragePercentage > fn.BranchCoveragePercent:
+		return fmt.Sprintf(
+			"branch coverage %v%% for function %v in package %v did not meet minimum %v%%",
+			fn.BranchCoveragePercent,
+			fn.Name,
+			pkg,
+			fn.MinBranchCoveragePercentage,
+		)
+	default:
+		return fmt.Sprintf(
+			"crap score %v for function %v in package %v exceeded maximum %v",
+			fn.CRAPScore,
+			fn.Name,
+			pkg,
+			fn.MaxCRAPScore,
+		)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_WriteJUnit(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := Report{
+		Packages: []PackageReport{
+			{
+				Path:                  "foo/bar",
+				ExecutedCount:         10,
+				StatementCount:        10,
+				CoveragePercent:       100,
+				MinCoveragePercentage: 50,
+				Pass:                  true,
+			},
+			{
+				Path:                  "foo/baz",
+				ExecutedCount:         1,
+				StatementCount:        10,
+				CoveragePercent:       10,
+				MinCoveragePercentage: 66.6,
+			},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteJUnit(buf, r)).To(Succeed())
+	g.Expect(buf.String()).To(HavePrefix(xml.Header))
+
+	actual := junitTestSuites{}
+	g.Expect(xml.Unmarshal(buf.Bytes(), &actual)).To(Succeed())
+	g.Expect(actual.Tests).To(Equal(2))
+	g.Expect(actual.Failures).To(Equal(1))
+	g.Expect(actual.Suites).To(HaveLen(1))
+
+	cases := actual.Suites[0].TestCases
+	g.Expect(cases).To(HaveLen(2))
+	g.Expect(cases[0].Name).To(Equal("foo/bar"))
+	g.Expect(cases[0].Failure).To(BeNil())
+	g.Expect(cases[1].Name).To(Equal("foo/baz"))
+	g.Expect(cases[1].Failure).ToNot(BeNil())
+	g.Expect(cases[1].Failure.Message).To(Equal("coverage 10% for package foo/baz did not meet minimum 66.6%"))
+}
+
+func Test_WriteJUnit_FunctionRules(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := Report{
+		Packages: []PackageReport{
+			{
+				Path:            "foo/auth",
+				CoveragePercent: 80,
+				Pass:            true,
+				Functions: []FunctionReport{
+					{
+						Name:                  "Login",
+						SrcPath:               "/src/foo/auth/login.go",
+						StartLine:             12,
+						CoveragePercent:       50,
+						MinCoveragePercentage: 95,
+						Rule:                  "foo/auth Login",
+					},
+					{Name: "Logout", CoveragePercent: 100, Pass: true},
+				},
+			},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteJUnit(buf, r)).To(Succeed())
+
+	actual := junitTestSuites{}
+	g.Expect(xml.Unmarshal(buf.Bytes(), &actual)).To(Succeed())
+	g.Expect(actual.Tests).To(Equal(2))
+	g.Expect(actual.Failures).To(Equal(1))
+
+	cases := actual.Suites[0].TestCases
+	g.Expect(cases).To(HaveLen(2))
+	g.Expect(cases[0].Failure).To(BeNil())
+	g.Expect(cases[1].Name).To(Equal("foo/auth.Login"))
+	g.Expect(cases[1].File).To(Equal("/src/foo/auth/login.go"))
+	g.Expect(cases[1].Line).To(Equal(12))
+	g.Expect(cases[1].Failure).ToNot(BeNil())
+	g.Expect(cases[1].Failure.Message).To(Equal("coverage 50% for function Login in package foo/auth did not meet minimum 95%"))
+}
+
+func Test_WriteJUnit_UntestedPackage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := Report{
+		Packages: []PackageReport{
+			{Path: "foo/bar", CoveragePercent: 0, StatementCount: 4, Untested: true},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteJUnit(buf, r)).To(Succeed())
+
+	actual := junitTestSuites{}
+	g.Expect(xml.Unmarshal(buf.Bytes(), &actual)).To(Succeed())
+
+	cases := actual.Suites[0].TestCases
+	g.Expect(cases).To(HaveLen(1))
+	g.Expect(cases[0].SystemOut).To(HavePrefix("no tests"))
+	g.Expect(cases[0].Failure).ToNot(BeNil())
+	g.Expect(cases[0].Failure.Message).To(Equal("package foo/bar has no tests"))
+}
+
+func Test_WriteJUnit_BranchCoverage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := Report{
+		Packages: []PackageReport{
+			{
+				Path:                        "foo/bar",
+				CoveragePercent:             100,
+				BranchCoveragePercent:       50,
+				MinBranchCoveragePercentage: 80,
+			},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteJUnit(buf, r)).To(Succeed())
+
+	actual := junitTestSuites{}
+	g.Expect(xml.Unmarshal(buf.Bytes(), &actual)).To(Succeed())
+
+	cases := actual.Suites[0].TestCases
+	g.Expect(cases).To(HaveLen(1))
+	g.Expect(cases[0].Failure).ToNot(BeNil())
+	g.Expect(cases[0].Failure.Message).To(Equal("branch coverage 50% for package foo/bar did not meet minimum 80%"))
+}
+
+func Test_WriteJUnit_CRAPScore(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := Report{
+		Packages: []PackageReport{
+			{
+				Path:            "foo/bar",
+				CoveragePercent: 50,
+				Pass:            true,
+				Functions: []FunctionReport{
+					{Name: "Get", CRAPScore: 1, MaxCRAPScore: 20, Pass: true},
+					{Name: "Parse", CoveragePercent: 0, CRAPScore: 30, MaxCRAPScore: 20},
+				},
+			},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteJUnit(buf, r)).To(Succeed())
+
+	actual := junitTestSuites{}
+	g.Expect(xml.Unmarshal(buf.Bytes(), &actual)).To(Succeed())
+
+	cases := actual.Suites[0].TestCases
+	g.Expect(cases).To(HaveLen(2))
+	g.Expect(cases[1].Name).To(Equal("foo/bar.Parse"))
+	g.Expect(cases[1].Failure).ToNot(BeNil())
+	g.Expect(cases[1].Failure.Message).To(Equal("crap score 30 for function Parse in package foo/bar exceeded maximum 20"))
+}
+
+func Test_WriteJUnit_Trees(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := Report{
+		Trees: []TreeReport{
+			{Path: "foo/internal/billing/...", CoveragePercent: 50, MinCoveragePercentage: 80},
+			{Path: "foo/internal/auth", MinCoveragePercentage: 10, Missing: true},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteJUnit(buf, r)).To(Succeed())
+
+	actual := junitTestSuites{}
+	g.Expect(xml.Unmarshal(buf.Bytes(), &actual)).To(Succeed())
+
+	cases := actual.Suites[0].TestCases
+	g.Expect(cases).To(HaveLen(2))
+	g.Expect(cases[0].Name).To(Equal("foo/internal/billing/..."))
+	g.Expect(cases[0].Failure.Message).To(Equal("coverage 50% for tree foo/internal/billing/... did not meet minimum 80%"))
+	g.Expect(cases[1].Name).To(Equal("foo/internal/auth/..."))
+	g.Expect(cases[1].Failure.Message).To(Equal("tree foo/internal/auth has no packages"))
+}
+
+func Test_WriteJUnit_Total(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := Report{
+		Total: TotalReport{CoveragePercent: 41.66, MinCoveragePercentage: 50, ExecutedCount: 5, StatementCount: 12},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteJUnit(buf, r)).To(Succeed())
+
+	actual := junitTestSuites{}
+	g.Expect(xml.Unmarshal(buf.Bytes(), &actual)).To(Succeed())
+
+	cases := actual.Suites[0].TestCases
+	g.Expect(cases).To(HaveLen(1))
+	g.Expect(cases[0].Name).To(Equal("total"))
+	g.Expect(cases[0].Failure.Message).To(Equal("total coverage 41.66% did not meet minimum 50%"))
+}
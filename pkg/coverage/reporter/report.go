@@ -0,0 +1,180 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/config"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"github.com/cvgw/gocheckcov/pkg/coverage/risk"
+)
+
+// Report is the result of verifying every package. UntestedPackages and UntestedStatementCount count the packages
+// which have no test files and the statements in them. Trees holds the directories with a tree rule in the config.
+type Report struct {
+	Pass                   bool            `json:"pass"`
+	UntestedPackages       int             `json:"untested_packages"`
+	UntestedStatementCount int64           `json:"untested_statement_count"`
+	Total                  TotalReport     `json:"total"`
+	Modules                []TotalReport   `json:"modules"`
+	Packages               []PackageReport `json:"packages"`
+	Trees                  []TreeReport    `json:"trees,omitempty"`
+}
+
+type PackageReport struct {
+	Path                        string           `json:"path"`
+	ExecutedCount               int64            `json:"executed_count"`
+	StatementCount              int64            `json:"statement_count"`
+	CoveragePercent             float64          `json:"coverage_percentage"`
+	MinCoveragePercentage       float64          `json:"min_coverage_percentage"`
+	CoveredBranchCount          int64            `json:"covered_branch_count"`
+	BranchCount                 int64            `json:"branch_count"`
+	BranchCoveragePercent       float64          `json:"branch_coverage_percentage"`
+	MinBranchCoveragePercentage float64          `json:"min_branch_coverage_percentage"`
+	Rule                        string           `json:"rule"`
+	Pass                        bool             `json:"pass"`
+	Untested                    bool             `json:"untested"`
+	Functions                   []FunctionReport `json:"functions"`
+}
+
+// newPackageReport returns the report for the package of cfgPkg. It passes when it meets its minimum statement and
+// branch coverage and none of its functions fail.
+func newPackageReport(
+	cfgPkg packageConfig,
+	pc *analyzer.PackageCoverages,
+	cfg *config.ConfigFile,
+	maxCRAP float64,
+) (PackageReport, error) {
+	cov, ok := pc.Coverage(cfgPkg.Name)
+	if !ok {
+		return PackageReport{}, fmt.Errorf("could not get coverage for package %v", cfgPkg.ConfigPackage)
+	}
+
+	pr := PackageReport{
+		Path:                        cfgPkg.Name,
+		ExecutedCount:               cov.ExecutedCount,
+		StatementCount:              cov.StatementCount,
+		CoveragePercent:             cov.CoveragePercent,
+		MinCoveragePercentage:       cfgPkg.MinCoveragePercentage,
+		CoveredBranchCount:          cov.CoveredBranchCount,
+		BranchCount:                 cov.BranchCount,
+		BranchCoveragePercent:       cov.BranchCoveragePercent,
+		MinBranchCoveragePercentage: cfgPkg.MinBranchCoveragePercentage,
+		Rule:                        cfgPkg.Rule,
+		Untested:                    cov.Untested,
+		Functions:                   newFunctionReports(cfgPkg.Name, cov.Functions, cfg, maxCRAP),
+	}
+	pr.Pass = pr.meetsMinimum()
+
+	return pr, nil
+}
+
+// meetsMinimum reports whether the package met its minimum statement and branch coverage
+func (pr PackageReport) meetsMinimum() bool {
+	return pr.MinCoveragePercentage <= pr.CoveragePercent &&
+		pr.MinBranchCoveragePercentage <= pr.BranchCoveragePercent
+}
+
+// FunctionReport is the coverage of a single function. MinCoveragePercentage, MinBranchCoveragePercentage and Rule are
+// only set for functions that matched a function rule in the config.
+type FunctionReport struct {
+	Name                        string  `json:"name"`
+	SrcPath                     string  `json:"src_path"`
+	StartLine                   int     `json:"start_line"`
+	EndLine                     int     `json:"end_line"`
+	ExecutedCount               int64   `json:"executed_count"`
+	StatementCount              int64   `json:"statement_count"`
+	CoveragePercent             float64 `json:"coverage_percentage"`
+	MinCoveragePercentage       float64 `json:"min_coverage_percentage,omitempty"`
+	CoveredBranchCount          int64   `json:"covered_branch_count"`
+	BranchCount                 int64   `json:"branch_count"`
+	BranchCoveragePercent       float64 `json:"branch_coverage_percentage"`
+	MinBranchCoveragePercentage float64 `json:"min_branch_coverage_percentage,omitempty"`
+	Complexity                  int     `json:"complexity"`
+	CRAPScore                   float64 `json:"crap_score"`
+	MaxCRAPScore                float64 `json:"max_crap_score,omitempty"`
+	Rule                        string  `json:"rule,omitempty"`
+	Pass                        bool    `json:"pass"`
+}
+
+// newFunctionReports returns a report for each function. Functions fail when they do not meet their function rule
+// or their CRAP score is above maxCRAP, if it is set.
+func newFunctionReports(
+	pkg string,
+	functions []profile.FunctionCoverage,
+	cfg *config.ConfigFile,
+	maxCRAP float64,
+) []FunctionReport {
+	out := make([]FunctionReport, 0, len(functions))
+
+	for _, function := range functions {
+		fr := FunctionReport{
+			Name:                  function.Name,
+			SrcPath:               function.Function.SrcPath,
+			StartLine:             function.Function.StartLine,
+			EndLine:               function.Function.EndLine,
+			ExecutedCount:         function.CoveredCount,
+			StatementCount:        function.StatementCount,
+			CoveragePercent:       analyzer.CoveragePercent(function.CoveredCount, function.StatementCount),
+			CoveredBranchCount:    function.CoveredBranchCount,
+			BranchCount:           function.BranchCount,
+			BranchCoveragePercent: analyzer.CoveragePercent(function.CoveredBranchCount, function.BranchCount),
+			Complexity:            function.Function.Complexity,
+			CRAPScore:             risk.NewFunction(pkg, function).CRAPScore,
+			MaxCRAPScore:          maxCRAP,
+			Pass:                  true,
+		}
+
+		if cfg != nil {
+			if rule, ok := matchFunction(cfg, pkg, function); ok && !rule.Exclude {
+				fr.MinCoveragePercentage = rule.MinCoveragePercentage
+				fr.Rule = fmt.Sprintf("%v %v", rule.Package, rule.Name)
+				fr.MinBranchCoveragePercentage = rule.MinBranchCoveragePercentage
+			}
+		}
+
+		fr.Pass = fr.meetsRule() && !fr.exceedsMaxCRAP()
+
+		out = append(out, fr)
+	}
+
+	return out
+}
+
+// meetsRule reports whether the function met the minimums of its function rule, which functions without one always do
+func (fr FunctionReport) meetsRule() bool {
+	return fr.MinCoveragePercentage <= fr.CoveragePercent &&
+		fr.MinBranchCoveragePercentage <= fr.BranchCoveragePercent
+}
+
+// exceedsMaxCRAP reports whether the CRAP score of the function is above its maximum, if it has one
+func (fr FunctionReport) exceedsMaxCRAP() bool {
+	return fr.MaxCRAPScore > 0 && fr.CRAPScore > fr.MaxCRAPScore
+}
+
+func WriteJSON(w io.Writer, r Report) error {
+	return writeJSON(w, r)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
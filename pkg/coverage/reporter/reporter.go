@@ -19,8 +19,8 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
-	"math"
 	"os"
+	"path/filepath"
 	"sort"
 	"text/tabwriter"
 
@@ -32,6 +32,7 @@ import (
 
 	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
 	"github.com/cvgw/gocheckcov/pkg/coverage/config"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
 	"gopkg.in/yaml.v2"
 )
@@ -81,71 +82,340 @@ type Verifier struct {
 	MinCov         float64
 	PrintSrc       bool
 	PrintFunctions bool
+	// IncludeClosures prints the coverage of the closures of each function after it when printing functions
+	IncludeClosures bool
+	Explain         bool
+	// TotalMinCov is the minimum coverage of every package together when the config does not set one
+	TotalMinCov float64
+	// PrintTree prints the coverage of every directory of packages as an indented tree
+	PrintTree bool
+	// MaxCRAP is the maximum CRAP score of any function when the config does not set one, zero means no maximum
+	MaxCRAP float64
 }
 
+const (
+	ruleGlobalConfig = "min_coverage_percentage"
+	ruleMinCovFlag   = "--minimum-coverage"
+)
+
+// packageConfig is the configuration for a package and the name of the rule it came from
+type packageConfig struct {
+	config.ConfigPackage
+	Rule string
+}
+
+// ReportCoverage prints the coverage of each package and of the rules it was checked against. It returns the coverage
+// of each package, or an error naming the first kind of rule which failed. Both come from the result of Report.
 func (v Verifier) ReportCoverage(
 	packageToFunctions map[string][]profile.FunctionCoverage,
 	printFunctions bool,
 	configFile []byte,
 ) (map[string]float64, error) {
-	pkgToCoverage := make(map[string]float64)
+	r, pc, err := v.report(packageToFunctions, configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgToCoverage := make(map[string]float64, len(r.Packages))
+
+	for _, pr := range r.Packages {
+		if v.Explain {
+			v.Out.Printf("pkg  %v\tmatched rule %v\n", pr.Path, pr.Rule)
+		}
+
+		cov, _ := pc.Coverage(pr.Path)
+		if err := v.printPackageReport(pr, cov.Functions); err != nil {
+			log.Debug(err)
+			return nil, err
+		}
+
+		v.printFunctionRules(pr)
+
+		pkgToCoverage[pr.Path] = pr.CoveragePercent
+	}
+
+	if v.PrintTree {
+		v.printTree(analyzer.NewTree(pc))
+	}
+
+	v.printRisk(r.Packages)
+	v.printTreeReports(r.Trees)
+	v.printTotals(r)
+	v.printUntested(r)
+
+	if err := reportError(r); err != nil {
+		return nil, err
+	}
+
+	return pkgToCoverage, nil
+}
+
+// Report evaluates each package against the config file, or the minimums set on the verifier without one, and
+// returns the results
+func (v Verifier) Report(
+	packageToFunctions map[string][]profile.FunctionCoverage,
+	configFile []byte,
+) (Report, error) {
+	r, _, err := v.report(packageToFunctions, configFile)
+
+	return r, err
+}
+
+// report returns the report together with the coverage of the packages it was built from, which leaves out the
+// functions excluded by the config
+func (v Verifier) report(
+	packageToFunctions map[string][]profile.FunctionCoverage,
+	configFile []byte,
+) (Report, *analyzer.PackageCoverages, error) {
+	cfg, err := parseConfig(configFile)
+	if err != nil {
+		return Report{}, nil, err
+	}
+
+	packageToFunctions = excludeFunctions(packageToFunctions, cfg)
 	pc := analyzer.NewPackageCoverages(packageToFunctions)
+	cfgPkgs := v.packageConfigs(sortedPackages(packageToFunctions), cfg)
 
-	fail := false
-	keys := make([]string, 0, len(packageToFunctions))
+	r := Report{Pass: true, Packages: make([]PackageReport, 0, len(cfgPkgs))}
 
-	for pkg := range packageToFunctions {
-		keys = append(keys, pkg)
+	for _, cfgPkg := range cfgPkgs {
+		pr, err := newPackageReport(cfgPkg, pc, cfg, v.maxCRAP(cfg))
+		if err != nil {
+			log.Debug(err)
+			return Report{}, nil, err
+		}
+
+		if pr.Untested {
+			r.UntestedPackages++
+			r.UntestedStatementCount += pr.StatementCount
+			pr.Pass = pr.Pass && !failOnUntested(cfg)
+		}
+
+		if !pr.Pass {
+			r.Pass = false
+		}
+
+		for _, fr := range pr.Functions {
+			if !fr.Pass {
+				r.Pass = false
+			}
+		}
+
+		r.Packages = append(r.Packages, pr)
 	}
 
-	sort.Strings(keys)
+	r.Trees = newTreeReports(analyzer.NewTree(pc), cfg)
+	r.Total = newTotalReport(pc.Total(), v.totalMinCov(cfg))
+	r.Modules = newModuleReports(pc.ModuleTotals())
+
+	if !r.Total.Pass || !treesPass(r.Trees) {
+		r.Pass = false
+	}
+
+	return r, pc, nil
+}
+
+// reportError returns an error for the first kind of rule the report failed, checked in the order packages, function
+// rules, CRAP scores, trees, the total and untested packages. It returns nil if the report passed.
+func reportError(r Report) error {
+	var pkgFail, funcFail, riskFail, untested bool
+
+	for _, pr := range r.Packages {
+		if !pr.meetsMinimum() {
+			pkgFail = true
+		} else if !pr.Pass {
+			untested = true
+		}
+
+		for _, fr := range pr.Functions {
+			funcFail = funcFail || !fr.meetsRule()
+			riskFail = riskFail || fr.exceedsMaxCRAP()
+		}
+	}
+
+	switch {
+	case pkgFail:
+		return fmt.Errorf("packages failed to meet minimum coverage")
+	case funcFail:
+		return fmt.Errorf("functions failed to meet minimum coverage")
+	case riskFail:
+		return fmt.Errorf("functions exceeded maximum crap score")
+	case !treesPass(r.Trees):
+		return fmt.Errorf("directory trees failed to meet minimum coverage")
+	case !r.Total.Pass:
+		return fmt.Errorf("total coverage failed to meet minimum coverage")
+	case untested:
+		return fmt.Errorf("packages have no test files")
+	}
 
-	for _, pkg := range keys {
-		var cfgPkg config.ConfigPackage
+	return nil
+}
 
-		if len(configFile) != 0 {
-			cfg := config.ConfigFile{}
-			if err := yaml.Unmarshal(configFile, &cfg); err != nil {
-				err = errors.Wrap(err, "could not unmarshal yaml for config file %v")
-				log.Debug(err)
+// printUntested prints the number of packages without test files and the number of statements in them
+func (v Verifier) printUntested(r Report) {
+	if r.UntestedPackages > 0 {
+		v.Out.Printf("untested packages %v\tstatements\t%v\n", r.UntestedPackages, r.UntestedStatementCount)
+	}
+}
 
-				return nil, err
+// printRisk prints each function whose CRAP score is above its maximum
+func (v Verifier) printRisk(packages []PackageReport) {
+	for _, pr := range packages {
+		for _, fr := range pr.Functions {
+			if !fr.exceedsMaxCRAP() {
+				continue
 			}
 
-			var ok bool
+			v.Out.Printf(
+				"crap score %v for function %v in package %v exceeded maximum %v\tcomplexity %v\tcoverage %v%%\n",
+				fr.CRAPScore,
+				fr.Name,
+				pr.Path,
+				fr.MaxCRAPScore,
+				fr.Complexity,
+				fr.CoveragePercent,
+			)
+		}
+	}
+}
 
-			cfgPkg, ok = cfg.GetPackage(pkg)
-			if !ok {
-				log.Debugf("could not find package for name %v", pkg)
+// maxCRAP returns the maximum CRAP score from the config, falling back to the one set on the verifier
+func (v Verifier) maxCRAP(cfg *config.ConfigFile) float64 {
+	if cfg != nil && cfg.MaxCRAPScore > 0 {
+		return cfg.MaxCRAPScore
+	}
+
+	return v.MaxCRAP
+}
 
-				cfgPkg = config.ConfigPackage{
+func failOnUntested(cfg *config.ConfigFile) bool {
+	return cfg != nil && cfg.FailOnUntestedPackages
+}
+
+// parseConfig unmarshals and validates the config file. It returns nil if there is no config file.
+func parseConfig(configFile []byte) (*config.ConfigFile, error) {
+	if len(configFile) == 0 {
+		return nil, nil
+	}
+
+	cfg := &config.ConfigFile{}
+	if err := yaml.Unmarshal(configFile, cfg); err != nil {
+		err = errors.Wrap(err, "could not unmarshal yaml for config file")
+		log.Debug(err)
+
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Debug(err)
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (v Verifier) packageConfigs(pkgs []string, cfg *config.ConfigFile) []packageConfig {
+	cfgPkgs := make([]packageConfig, 0, len(pkgs))
+
+	if cfg == nil {
+		for _, pkg := range pkgs {
+			cfgPkgs = append(cfgPkgs, packageConfig{
+				ConfigPackage: config.ConfigPackage{
 					Name:                  pkg,
-					MinCoveragePercentage: cfg.MinCoveragePercentage,
-				}
+					MinCoveragePercentage: v.MinCov,
+				},
+				Rule: ruleMinCovFlag,
+			})
+		}
+
+		return cfgPkgs
+	}
+
+	for _, pkg := range pkgs {
+		cfgPkg := packageConfig{
+			ConfigPackage: config.ConfigPackage{
+				Name:                        pkg,
+				MinCoveragePercentage:       cfg.MinCoveragePercentage,
+				MinBranchCoveragePercentage: cfg.MinBranchCoveragePercentage,
+			},
+			Rule: ruleGlobalConfig,
+		}
+
+		if rule, ok := cfg.MatchPackage(pkg); ok {
+			cfgPkg.MinCoveragePercentage = rule.MinCoveragePercentage
+			cfgPkg.Rule = rule.Name
+
+			if rule.MinBranchCoveragePercentage > 0 {
+				cfgPkg.MinBranchCoveragePercentage = rule.MinBranchCoveragePercentage
 			}
 		} else {
-			cfgPkg = config.ConfigPackage{
-				Name:                  pkg,
-				MinCoveragePercentage: v.MinCov,
-			}
+			log.Debugf("could not find package for name %v", pkg)
 		}
 
-		ok, err := v.VerifyCoverage(cfgPkg, pc)
-		if err != nil {
-			log.Debug(err)
-			return nil, err
-		}
+		cfgPkgs = append(cfgPkgs, cfgPkg)
+	}
+
+	return cfgPkgs
+}
+
+// ExcludeFunctions returns the functions of each package without the functions excluded by the config file
+func ExcludeFunctions(
+	packageToFunctions map[string][]profile.FunctionCoverage,
+	configFile []byte,
+) (map[string][]profile.FunctionCoverage, error) {
+	cfg, err := parseConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return excludeFunctions(packageToFunctions, cfg), nil
+}
 
-		if !ok {
-			fail = true
+// excludeFunctions returns the functions of each package without the functions excluded by the config
+func excludeFunctions(
+	packageToFunctions map[string][]profile.FunctionCoverage,
+	cfg *config.ConfigFile,
+) map[string][]profile.FunctionCoverage {
+	if cfg == nil || len(cfg.Functions) == 0 {
+		return packageToFunctions
+	}
+
+	out := make(map[string][]profile.FunctionCoverage, len(packageToFunctions))
+
+	for pkg, functions := range packageToFunctions {
+		kept := make([]profile.FunctionCoverage, 0, len(functions))
+
+		for _, fc := range functions {
+			if rule, ok := matchFunction(cfg, pkg, fc); ok && rule.Exclude {
+				log.Debugf("excluding function %v in package %v", fc.Name, pkg)
+				continue
+			}
+
+			kept = append(kept, fc)
 		}
+
+		out[pkg] = kept
 	}
 
-	if fail {
-		return nil, fmt.Errorf("packages failed to meet minimum coverage")
+	return out
+}
+
+// matchFunction returns the function rule for fc, which rules may name by its method name as well as by its
+// receiver qualified name
+func matchFunction(cfg *config.ConfigFile, pkg string, fc profile.FunctionCoverage) (config.ConfigFunction, bool) {
+	return cfg.MatchFunction(pkg, fc.Name, fc.Function.MethodName())
+}
+
+func sortedPackages(packageToFunctions map[string][]profile.FunctionCoverage) []string {
+	keys := make([]string, 0, len(packageToFunctions))
+
+	for pkg := range packageToFunctions {
+		keys = append(keys, pkg)
 	}
 
-	return pkgToCoverage, nil
+	sort.Strings(keys)
+
+	return keys
 }
 
 func (v Verifier) VerifyCoverage(pkg config.ConfigPackage, pc *analyzer.PackageCoverages) (bool, error) {
@@ -156,35 +426,131 @@ func (v Verifier) VerifyCoverage(pkg config.ConfigPackage, pc *analyzer.PackageC
 		return false, err
 	}
 
-	cov, ok := pc.Coverage(pkg.Name)
-
-	if !ok {
-		err := fmt.Errorf("could not get coverage for package %v", pkg)
+	pr, err := newPackageReport(packageConfig{ConfigPackage: pkg}, pc, nil, 0)
+	if err != nil {
 		log.Debug(err)
+		return false, err
+	}
 
+	cov, _ := pc.Coverage(pkg.Name)
+	if err := v.printPackageReport(pr, cov.Functions); err != nil {
 		return false, err
 	}
 
-	v.Out.Printf(
-		"pkg  %v\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v\n",
-		pkg.Name,
-		cov.CoveragePercent,
-		pkg.MinCoveragePercentage,
-		cov.ExecutedCount,
-		cov.StatementCount,
-	)
+	return pr.meetsMinimum(), nil
+}
+
+// printPackageReport prints the coverage of the package, followed by its functions when PrintFunctions is set and
+// the branch coverage it missed when it met its statement minimum
+func (v Verifier) printPackageReport(pr PackageReport, functions []profile.FunctionCoverage) error {
+	if pr.MinBranchCoveragePercentage > 0 {
+		v.Out.Printf(
+			"pkg  %v\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v\tbranch coverage %v%% \tminimum %v%% \tbranches\t%v/%v\n",
+			pr.Path,
+			pr.CoveragePercent,
+			pr.MinCoveragePercentage,
+			pr.ExecutedCount,
+			pr.StatementCount,
+			pr.BranchCoveragePercent,
+			pr.MinBranchCoveragePercentage,
+			pr.CoveredBranchCount,
+			pr.BranchCount,
+		)
+	} else {
+		v.Out.Printf(
+			"pkg  %v\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v\n",
+			pr.Path,
+			pr.CoveragePercent,
+			pr.MinCoveragePercentage,
+			pr.ExecutedCount,
+			pr.StatementCount,
+		)
+	}
+
+	if pr.Untested {
+		v.Out.Printf("pkg  %v\tno tests\n", pr.Path)
+	}
 
 	if v.PrintFunctions {
-		if err := v.PrintFunctionReport(cov.Functions); err != nil {
-			return false, err
+		if err := v.PrintFunctionReport(functions); err != nil {
+			return err
 		}
 	}
 
-	if pkg.MinCoveragePercentage > cov.CoveragePercent {
-		return false, nil
+	if pr.MinCoveragePercentage <= pr.CoveragePercent && pr.MinBranchCoveragePercentage > pr.BranchCoveragePercent {
+		v.Out.Printf(
+			"branch coverage %v%% for package %v did not meet minimum %v%%\n",
+			pr.BranchCoveragePercent,
+			pr.Path,
+			pr.MinBranchCoveragePercentage,
+		)
+	}
+
+	return nil
+}
+
+// printFunctionRules prints the coverage of each function of the package with a function rule and the minimums it
+// did not meet
+func (v Verifier) printFunctionRules(pr PackageReport) {
+	for _, fr := range pr.Functions {
+		if fr.Rule == "" {
+			continue
+		}
+
+		if v.Explain {
+			v.Out.Printf("func %v\tmatched rule %v\n", fr.Name, fr.Rule)
+		}
+
+		v.Out.Printf(
+			"func %v\t%v\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v\tbranches\t%v/%v\n",
+			fr.Name,
+			fileLocation(fr.SrcPath, fr.StartLine),
+			fr.CoveragePercent,
+			fr.MinCoveragePercentage,
+			fr.ExecutedCount,
+			fr.StatementCount,
+			fr.CoveredBranchCount,
+			fr.BranchCount,
+		)
+
+		if fr.MinBranchCoveragePercentage > fr.BranchCoveragePercent {
+			v.Out.Printf(
+				"branch coverage %v%% for function %v in package %v did not meet minimum %v%%\n",
+				fr.BranchCoveragePercent,
+				fr.Name,
+				pr.Path,
+				fr.MinBranchCoveragePercentage,
+			)
+		}
+
+		if fr.MinCoveragePercentage > fr.CoveragePercent {
+			v.Out.Printf(
+				"coverage %v%% for function %v in package %v did not meet minimum %v%%\n",
+				fr.CoveragePercent,
+				fr.Name,
+				pr.Path,
+				fr.MinCoveragePercentage,
+			)
+		}
 	}
+}
 
-	return true, nil
+// PrintIgnoredRegions prints every region that is left out of coverage by an ignore directive
+func (v Verifier) PrintIgnoredRegions(packageToFunctions map[string][]profile.FunctionCoverage) {
+	for _, pkg := range sortedPackages(packageToFunctions) {
+		for _, fc := range packageToFunctions[pkg] {
+			for _, r := range fc.Function.Ignored {
+				v.Out.Printf(
+					"ignored %v:%v-%v\tfunc %v\t%v\n",
+					fc.Function.SrcPath,
+					r.StartLine,
+					r.EndLine,
+					fc.Name,
+					r.Reason,
+				)
+			}
+		}
+	}
 }
 
 func (v Verifier) PrintFunctionReport(functions []profile.FunctionCoverage) error {
@@ -193,20 +559,21 @@ func (v Verifier) PrintFunctionReport(functions []profile.FunctionCoverage) erro
 			continue
 		}
 
-		var executedStatementsCount int64
-
-		executedStatementsCount += function.CoveredCount
-
-		val := (float64(executedStatementsCount) / float64(function.StatementCount)) * 10000
-		percent := (math.Floor(val) / 100)
 		v.Out.Printf(
-			"func %v\tcoverage %v%% \t\tstatements\t%v/%v\n",
+			"func %v\t%v\tcoverage %v%% \t\tstatements\t%v/%v\tbranches\t%v/%v\n",
 			function.Name,
-			percent,
-			executedStatementsCount,
+			functionLocation(function.Function),
+			analyzer.CoveragePercent(function.CoveredCount, function.StatementCount),
+			function.CoveredCount,
 			function.StatementCount,
+			function.CoveredBranchCount,
+			function.BranchCount,
 		)
 
+		if v.IncludeClosures {
+			v.printClosureReport(function.Closures)
+		}
+
 		if v.PrintSrc {
 			filePath := function.Function.SrcPath
 			src, err := ioutil.ReadFile(filePath)
@@ -226,53 +593,59 @@ func (v Verifier) PrintFunctionReport(functions []profile.FunctionCoverage) erro
 	return nil
 }
 
+// printClosureReport prints the coverage of each closure and the closures nested within it
+func (v Verifier) printClosureReport(closures []profile.FunctionCoverage) {
+	for _, closure := range closures {
+		if closure.StatementCount == 0 {
+			continue
+		}
+
+		v.Out.Printf(
+			"func %v\t%v\tcoverage %v%% \t\tstatements\t%v/%v\tbranches\t%v/%v\n",
+			closure.Name,
+			functionLocation(closure.Function),
+			analyzer.CoveragePercent(closure.CoveredCount, closure.StatementCount),
+			closure.CoveredCount,
+			closure.StatementCount,
+			closure.CoveredBranchCount,
+			closure.BranchCount,
+		)
+
+		v.printClosureReport(closure.Closures)
+	}
+}
+
+// functionLocation returns the file name and line the function starts on
+func functionLocation(f functions.Function) string {
+	return fileLocation(f.SrcPath, f.StartLine)
+}
+
+// fileLocation returns the file name of srcPath and the line, or an empty string without a srcPath
+func fileLocation(srcPath string, line int) string {
+	if srcPath == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%v:%v", filepath.Base(srcPath), line)
+}
+
 func (v *Verifier) printSrcWithCoverage(fc profile.FunctionCoverage, src []byte) error {
 	boundaries := []cover.Boundary{}
 	if fc.Profile != nil {
 		boundaries = fc.Profile.Boundaries(src)
 	}
 
-	red := color.New(color.FgRed)
-	green := color.New(color.FgGreen)
-	wht := color.New(color.FgWhite)
+	colors := map[coverageState]*color.Color{
+		stateNotApplicable: color.New(color.FgWhite),
+		stateCovered:       color.New(color.FgGreen),
+		stateUncovered:     color.New(color.FgRed),
+	}
 	out := bytes.NewBuffer(make([]byte, 0))
-	buf := bytes.NewBuffer(make([]byte, 0))
 
-	clr := wht
-
-	for i := fc.Function.StartOffset - 1; i < fc.Function.EndOffset+1; i++ {
-		for _, b := range boundaries {
-			if b.Offset != i {
-				continue
-			}
-
-			if b.Start {
-				if _, err := clr.Fprint(out, buf.String()); err != nil {
-					return err
-				}
-
-				buf = bytes.NewBuffer(make([]byte, 0))
-
-				if b.Norm == 0 {
-					clr = red
-				} else {
-					clr = green
-				}
-			} else {
-				if _, err := clr.Fprint(out, buf.String()); err != nil {
-					return err
-				}
-
-				buf = bytes.NewBuffer(make([]byte, 0))
-				clr = wht
-			}
+	for _, seg := range srcSegments(src, boundaries, fc.Function.StartOffset-1, fc.Function.EndOffset+1) {
+		if _, err := colors[seg.State].Fprint(out, seg.Text); err != nil {
+			return err
 		}
-
-		buf.Write([]byte{src[i]})
-	}
-
-	if _, err := clr.Fprint(out, buf.String()); err != nil {
-		return err
 	}
 
 	v.Out.Printf("%s\n", out.String())
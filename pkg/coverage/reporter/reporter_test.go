@@ -15,11 +15,14 @@
 package reporter
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
 	"github.com/cvgw/gocheckcov/mocks/coverage/mock_reporter"
 	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
 	"github.com/cvgw/gocheckcov/pkg/coverage/config"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
 	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
@@ -69,6 +72,47 @@ func Test_Verifier_VerifyCoverage(t *testing.T) {
 				},
 			}
 		},
+		"branch columns are left out without a branch minimum": func(ctrl *gomock.Controller) testcase {
+			mockLogger := mock_reporter.NewMocklogger(ctrl)
+			mockLogger.EXPECT().Printf(
+				"pkg  %v\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v\n",
+				"foo/bar", float64(50), float64(50), int64(1), int64(2),
+			)
+
+			return testcase{
+				verifier: &Verifier{Out: mockLogger},
+				coverages: analyzer.NewPackageCoverages(map[string][]profile.FunctionCoverage{
+					"foo/bar": []profile.FunctionCoverage{
+						{CoveredCount: 1, StatementCount: 2, CoveredBranchCount: 1, BranchCount: 4},
+					},
+				}),
+				pkg:    config.ConfigPackage{Name: "foo/bar", MinCoveragePercentage: 50},
+				result: true,
+			}
+		},
+		"branch columns are printed with a branch minimum": func(ctrl *gomock.Controller) testcase {
+			mockLogger := mock_reporter.NewMocklogger(ctrl)
+			mockLogger.EXPECT().Printf(
+				"pkg  %v\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v"+
+					"\tbranch coverage %v%% \tminimum %v%% \tbranches\t%v/%v\n",
+				"foo/bar", float64(50), float64(50), int64(1), int64(2), float64(25), float64(20), int64(1), int64(4),
+			)
+
+			return testcase{
+				verifier: &Verifier{Out: mockLogger},
+				coverages: analyzer.NewPackageCoverages(map[string][]profile.FunctionCoverage{
+					"foo/bar": []profile.FunctionCoverage{
+						{CoveredCount: 1, StatementCount: 2, CoveredBranchCount: 1, BranchCount: 4},
+					},
+				}),
+				pkg: config.ConfigPackage{
+					Name:                        "foo/bar",
+					MinCoveragePercentage:       50,
+					MinBranchCoveragePercentage: 20,
+				},
+				result: true,
+			}
+		},
 	}
 
 	for i := range testCases {
@@ -198,6 +242,89 @@ min_coverage_percentage: 20
 packages:
 - name: baz
   min_coverage_percentage: 0
+`),
+			}
+		},
+		"explain prints the matched rule": func(ctrl *gomock.Controller) testcase {
+			mockLogger := mock_reporter.NewMocklogger(ctrl)
+
+			mockLogger.EXPECT().Printf(gomock.Any(), "foo/bar", "foo/...").Times(1)
+			mockLogger.EXPECT().Printf(gomock.Any(), gomock.Any()).MinTimes(1)
+
+			return testcase{
+				verifier: &Verifier{Out: mockLogger, Explain: true},
+				input: map[string][]profile.FunctionCoverage{
+					"foo/bar": []profile.FunctionCoverage{
+						{CoveredCount: 1, StatementCount: 1},
+					},
+				},
+				configData: []byte(`
+packages:
+- name: foo/...
+  min_coverage_percentage: 10
+`),
+			}
+		},
+		"function does not meet min coverage from a function rule": func(ctrl *gomock.Controller) testcase {
+			mockLogger := mock_reporter.NewMocklogger(ctrl)
+
+			mockLogger.EXPECT().Printf(gomock.Any(), float64(50), "Charge", "foo/billing", float64(90)).Times(1)
+			mockLogger.EXPECT().Printf(gomock.Any(), gomock.Any()).MinTimes(1)
+
+			return testcase{
+				verifier: &Verifier{Out: mockLogger},
+				input: map[string][]profile.FunctionCoverage{
+					"foo/billing": []profile.FunctionCoverage{
+						{Name: "Charge", CoveredCount: 1, StatementCount: 2},
+						{Name: "helper", CoveredCount: 2, StatementCount: 2},
+					},
+				},
+				expectErr: true,
+				configData: []byte(`
+min_coverage_percentage: 50
+functions:
+- package: foo/billing
+  name: Charge
+  min_coverage_percentage: 90
+`),
+			}
+		},
+		"excluded function does not count toward package coverage": func(ctrl *gomock.Controller) testcase {
+			mockLogger := mock_reporter.NewMocklogger(ctrl)
+
+			mockLogger.EXPECT().Printf(gomock.Any(), gomock.Any()).MinTimes(1)
+
+			return testcase{
+				verifier: &Verifier{Out: mockLogger},
+				input: map[string][]profile.FunctionCoverage{
+					"foo/bar": []profile.FunctionCoverage{
+						{Name: "Serve", CoveredCount: 2, StatementCount: 2},
+						{Name: "generated", CoveredCount: 0, StatementCount: 8},
+					},
+				},
+				configData: []byte(`
+min_coverage_percentage: 100
+functions:
+- package: foo/...
+  name: "regexp:^generated"
+  exclude: true
+`),
+			}
+		},
+		"config file with a bad regexp": func(ctrl *gomock.Controller) testcase {
+			mockLogger := mock_reporter.NewMocklogger(ctrl)
+
+			return testcase{
+				verifier: &Verifier{Out: mockLogger},
+				input: map[string][]profile.FunctionCoverage{
+					"foo/bar": []profile.FunctionCoverage{
+						{CoveredCount: 1, StatementCount: 1},
+					},
+				},
+				expectErr: true,
+				configData: []byte(`
+packages:
+- name: "regexp:foo("
 `),
 			}
 		},
@@ -243,6 +370,25 @@ func Test_Verifier_PrintReport(t *testing.T) {
 		functions []profile.FunctionCoverage
 	}
 
+	closures := []profile.FunctionCoverage{
+		{
+			Name:               "Serve",
+			CoveredCount:       1,
+			StatementCount:     2,
+			CoveredBranchCount: 1,
+			BranchCount:        2,
+			Function:           functions.Function{Name: "Serve", SrcPath: "/src/foo/serve.go", StartLine: 3},
+			Closures: []profile.FunctionCoverage{
+				{
+					Name:           "Serve.func1",
+					CoveredCount:   0,
+					StatementCount: 1,
+					Function:       functions.Function{Name: "Serve.func1", SrcPath: "/src/foo/serve.go", StartLine: 4},
+				},
+			},
+		},
+	}
+
 	type tcFn func(*gomock.Controller) testcase
 
 	testCases := map[string]tcFn{
@@ -276,6 +422,27 @@ func Test_Verifier_PrintReport(t *testing.T) {
 				},
 			}
 		},
+		"closures are printed when included": func(ctrl *gomock.Controller) testcase {
+			mockLogger := mock_reporter.NewMocklogger(ctrl)
+			mockLogger.EXPECT().Printf(gomock.Any(), "Serve", "serve.go:3", float64(50), int64(1), int64(2), int64(1), int64(2)).Times(1)
+			mockLogger.EXPECT().Printf(gomock.Any(), "Serve.func1", "serve.go:4", float64(0), int64(0), int64(1), int64(0), int64(0)).Times(1)
+			mockLogger.EXPECT().Printf("\n").Times(1)
+
+			return testcase{
+				verifier:  &Verifier{Out: mockLogger, IncludeClosures: true},
+				functions: closures,
+			}
+		},
+		"closures are not printed by default": func(ctrl *gomock.Controller) testcase {
+			mockLogger := mock_reporter.NewMocklogger(ctrl)
+			mockLogger.EXPECT().Printf(gomock.Any(), "Serve", "serve.go:3", float64(50), int64(1), int64(2), int64(1), int64(2)).Times(1)
+			mockLogger.EXPECT().Printf("\n").Times(1)
+
+			return testcase{
+				verifier:  &Verifier{Out: mockLogger},
+				functions: closures,
+			}
+		},
 	}
 
 	for description := range testCases {
@@ -291,3 +458,332 @@ func Test_Verifier_PrintReport(t *testing.T) {
 		})
 	}
 }
+
+func Test_Verifier_Report(t *testing.T) {
+	type testcase struct {
+		verifier   *Verifier
+		input      map[string][]profile.FunctionCoverage
+		configData []byte
+		expectErr  bool
+		expectPass bool
+		expectPkgs []string
+		// expectFuncs is the number of functions reported per package when it differs from the input
+		expectFuncs map[string]int
+	}
+
+	testCases := map[string]testcase{
+		"empty function map": {
+			verifier:   &Verifier{},
+			input:      map[string][]profile.FunctionCoverage{},
+			expectPass: true,
+			expectPkgs: []string{},
+		},
+		"packages are sorted and meet global min": {
+			verifier: &Verifier{MinCov: 50},
+			input: map[string][]profile.FunctionCoverage{
+				"foo/bar": []profile.FunctionCoverage{
+					{CoveredCount: 1, StatementCount: 1},
+				},
+				"baz": []profile.FunctionCoverage{
+					{CoveredCount: 1, StatementCount: 2},
+				},
+			},
+			expectPass: true,
+			expectPkgs: []string{"baz", "foo/bar"},
+		},
+		"one package does not meet min coverage from config": {
+			verifier: &Verifier{},
+			input: map[string][]profile.FunctionCoverage{
+				"foo/bar": []profile.FunctionCoverage{
+					{CoveredCount: 0, StatementCount: 1},
+				},
+			},
+			configData: []byte(`
+packages:
+- name: foo/bar
+  min_coverage_percentage: 10
+`),
+			expectPkgs: []string{"foo/bar"},
+		},
+		"package does not meet global branch min from config": {
+			verifier: &Verifier{},
+			input: map[string][]profile.FunctionCoverage{
+				"foo/bar": []profile.FunctionCoverage{
+					{CoveredCount: 2, StatementCount: 2, CoveredBranchCount: 1, BranchCount: 2},
+				},
+			},
+			configData: []byte(`
+min_branch_coverage_percentage: 80
+packages:
+- name: foo/bar
+  min_coverage_percentage: 100
+`),
+			expectPkgs: []string{"foo/bar"},
+		},
+		"package rule branch min overrides the global one": {
+			verifier: &Verifier{},
+			input: map[string][]profile.FunctionCoverage{
+				"foo/bar": []profile.FunctionCoverage{
+					{CoveredCount: 2, StatementCount: 2, CoveredBranchCount: 1, BranchCount: 2},
+				},
+			},
+			configData: []byte(`
+min_branch_coverage_percentage: 80
+packages:
+- name: foo/bar
+  min_coverage_percentage: 100
+  min_branch_coverage_percentage: 50
+`),
+			expectPass: true,
+			expectPkgs: []string{"foo/bar"},
+		},
+		"function rule fails the report but not the package": {
+			verifier: &Verifier{},
+			input: map[string][]profile.FunctionCoverage{
+				"foo/bar": []profile.FunctionCoverage{
+					{Name: "Login", CoveredCount: 1, StatementCount: 2},
+					{Name: "debug", CoveredCount: 0, StatementCount: 2},
+				},
+			},
+			configData: []byte(`
+functions:
+- package: foo/bar
+  name: Login
+  min_coverage_percentage: 100
+- package: foo/bar
+  name: debug
+  exclude: true
+`),
+			expectPkgs:  []string{"foo/bar"},
+			expectFuncs: map[string]int{"foo/bar": 1},
+		},
+		"function rule matches a method by its method name": {
+			verifier: &Verifier{},
+			input: map[string][]profile.FunctionCoverage{
+				"foo/server": []profile.FunctionCoverage{
+					{
+						Name:           "(*Server).Start",
+						CoveredCount:   1,
+						StatementCount: 2,
+						Function:       functions.Function{Name: "(*Server).Start", Receiver: "*Server"},
+					},
+				},
+			},
+			configData: []byte(`
+functions:
+- package: foo/server
+  name: Start
+  min_coverage_percentage: 100
+`),
+			expectPkgs: []string{"foo/server"},
+		},
+		"bad config file": {
+			verifier: &Verifier{},
+			input: map[string][]profile.FunctionCoverage{
+				"foo/bar": []profile.FunctionCoverage{
+					{CoveredCount: 1, StatementCount: 1},
+				},
+			},
+			configData: []byte("meow"),
+			expectErr:  true,
+		},
+	}
+
+	for description := range testCases {
+		description := description
+
+		t.Run(description, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := testCases[description]
+
+			r, err := tc.verifier.Report(tc.input, tc.configData)
+			if tc.expectErr {
+				g.Expect(err).ToNot(BeNil())
+				return
+			}
+
+			g.Expect(err).To(BeNil())
+			g.Expect(r.Pass).To(Equal(tc.expectPass))
+
+			pkgs := make([]string, 0, len(r.Packages))
+			for _, p := range r.Packages {
+				pkgs = append(pkgs, p.Path)
+
+				funcCount, ok := tc.expectFuncs[p.Path]
+				if !ok {
+					funcCount = len(tc.input[p.Path])
+				}
+
+				g.Expect(p.Functions).To(HaveLen(funcCount))
+			}
+
+			g.Expect(pkgs).To(Equal(tc.expectPkgs))
+		})
+	}
+}
+
+func Test_Verifier_UntestedPackages(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	input := map[string][]profile.FunctionCoverage{
+		"foo/bar": []profile.FunctionCoverage{
+			{
+				CoveredCount:   0,
+				StatementCount: 3,
+				Untested:       true,
+			},
+		},
+	}
+	failConfig := []byte("fail_on_untested_packages: true\n")
+
+	r, err := (&Verifier{}).Report(input, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(r.Pass).To(BeTrue())
+	g.Expect(r.UntestedPackages).To(Equal(1))
+	g.Expect(r.UntestedStatementCount).To(Equal(int64(3)))
+	g.Expect(r.Packages[0].Untested).To(BeTrue())
+
+	r, err = (&Verifier{}).Report(input, failConfig)
+	g.Expect(err).To(BeNil())
+	g.Expect(r.Pass).To(BeFalse())
+	g.Expect(r.Packages[0].Pass).To(BeFalse())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := mock_reporter.NewMocklogger(ctrl)
+	mockLogger.EXPECT().Printf("pkg  %v\tno tests\n", "foo/bar").Times(3)
+	mockLogger.EXPECT().Printf("untested packages %v\tstatements\t%v\n", 1, int64(3)).Times(3)
+	mockLogger.EXPECT().Printf(gomock.Any(), gomock.Any()).MinTimes(1)
+
+	_, err = (&Verifier{Out: mockLogger}).ReportCoverage(input, false, nil)
+	g.Expect(err).To(BeNil())
+
+	_, err = (&Verifier{Out: mockLogger}).ReportCoverage(input, false, failConfig)
+	g.Expect(err).ToNot(BeNil())
+
+	_, err = (&Verifier{Out: mockLogger, MinCov: 50}).ReportCoverage(input, false, nil)
+	g.Expect(err).To(MatchError("packages failed to meet minimum coverage"))
+}
+
+func Test_reportError(t *testing.T) {
+	passing := PackageReport{Path: "foo/bar", CoveragePercent: 50, MinCoveragePercentage: 40, Pass: true}
+	total := TotalReport{Pass: true}
+
+	type testcase struct {
+		report   Report
+		expected string
+	}
+
+	testCases := map[string]testcase{
+		"passing report": {
+			report: Report{Pass: true, Total: total, Packages: []PackageReport{passing}},
+		},
+		"package below its minimum wins over untested packages": {
+			report: Report{Total: total, Packages: []PackageReport{
+				{Path: "foo/baz", MinCoveragePercentage: 10, Untested: true},
+				{Path: "foo/qux", Untested: true},
+			}},
+			expected: "packages failed to meet minimum coverage",
+		},
+		"function below its rule": {
+			report: Report{Total: total, Packages: []PackageReport{{
+				Path:      "foo/bar",
+				Pass:      true,
+				Functions: []FunctionReport{{Name: "Serve", MinCoveragePercentage: 80, CoveragePercent: 50}},
+			}}},
+			expected: "functions failed to meet minimum coverage",
+		},
+		"function above the maximum crap score": {
+			report: Report{Total: total, Packages: []PackageReport{{
+				Path:      "foo/bar",
+				Pass:      true,
+				Functions: []FunctionReport{{Name: "Serve", CRAPScore: 40, MaxCRAPScore: 30}},
+			}}},
+			expected: "functions exceeded maximum crap score",
+		},
+		"tree below its minimum": {
+			report:   Report{Total: total, Trees: []TreeReport{{Path: "foo"}}},
+			expected: "directory trees failed to meet minimum coverage",
+		},
+		"total below its minimum": {
+			report:   Report{Packages: []PackageReport{passing}},
+			expected: "total coverage failed to meet minimum coverage",
+		},
+		"untested package": {
+			report:   Report{Total: total, Packages: []PackageReport{{Path: "foo/bar", Untested: true}}},
+			expected: "packages have no test files",
+		},
+	}
+
+	for desc := range testCases {
+		desc := desc
+		t.Run(desc, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tc := testCases[desc]
+
+			err := reportError(tc.report)
+			if tc.expected == "" {
+				g.Expect(err).To(BeNil())
+			} else {
+				g.Expect(err).To(MatchError(tc.expected))
+			}
+		})
+	}
+}
+
+func Test_ExcludeFunctions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	input := map[string][]profile.FunctionCoverage{
+		"foo/bar": []profile.FunctionCoverage{
+			{Name: "Serve", CoveredCount: 2, StatementCount: 2},
+			{Name: "generated", CoveredCount: 0, StatementCount: 8},
+		},
+	}
+
+	out, err := ExcludeFunctions(input, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(out).To(Equal(input))
+
+	out, err = ExcludeFunctions(input, []byte(`
+functions:
+- package: foo/...
+  name: "regexp:^generated"
+  exclude: true
+`))
+	g.Expect(err).To(BeNil())
+	g.Expect(out).To(Equal(map[string][]profile.FunctionCoverage{
+		"foo/bar": []profile.FunctionCoverage{{Name: "Serve", CoveredCount: 2, StatementCount: 2}},
+	}))
+
+	_, err = ExcludeFunctions(input, []byte("functions: [\n"))
+	g.Expect(err).ToNot(BeNil())
+}
+
+func Test_WriteJSON(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	r := Report{
+		Packages: []PackageReport{
+			{
+				Path:                  "foo/bar",
+				ExecutedCount:         1,
+				StatementCount:        3,
+				CoveragePercent:       33.33,
+				MinCoveragePercentage: 50,
+				Functions: []FunctionReport{
+					{Name: "Meow", ExecutedCount: 1, StatementCount: 3, CoveragePercent: 33.33},
+				},
+			},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	g.Expect(WriteJSON(buf, r)).To(Succeed())
+
+	actual := Report{}
+	g.Expect(json.Unmarshal(buf.Bytes(), &actual)).To(Succeed())
+	g.Expect(actual).To(Equal(r))
+	g.Expect(buf.String()).To(ContainSubstring(`"min_coverage_percentage": 50`))
+}
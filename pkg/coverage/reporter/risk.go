@@ -0,0 +1,50 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/risk"
+)
+
+func WriteRiskJSON(w io.Writer, functions []risk.Function) error {
+	return writeJSON(w, functions)
+}
+
+// PrintRisk prints the CRAP score, complexity and coverage of each function
+func (v Verifier) PrintRisk(functions []risk.Function) {
+	for _, f := range functions {
+		v.Out.Printf(
+			"crap %v\tcomplexity %v\tcoverage %v%% \tfunc %v\t%v\n",
+			f.CRAPScore,
+			f.Complexity,
+			f.CoveragePercent,
+			f.Name,
+			riskLocation(f),
+		)
+	}
+}
+
+// riskLocation returns the package, file name and line the function starts on
+func riskLocation(f risk.Function) string {
+	if f.SrcPath == "" {
+		return f.Package
+	}
+
+	return fmt.Sprintf("%v:%v", filepath.Join(f.Package, filepath.Base(f.SrcPath)), f.StartLine)
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/mocks/coverage/mock_reporter"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"github.com/cvgw/gocheckcov/pkg/coverage/risk"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Verifier_PrintRisk(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockLogger := mock_reporter.NewMocklogger(ctrl)
+	mockLogger.EXPECT().Printf(gomock.Any(), 30.0, 5, 0.0, "Parse", "foo/bar/parse.go:12").Times(1)
+	mockLogger.EXPECT().Printf(gomock.Any(), 1.0, 1, 100.0, "Get", "foo/bar").Times(1)
+
+	v := Verifier{Out: mockLogger}
+	v.PrintRisk([]risk.Function{
+		{Package: "foo/bar", Name: "Parse", SrcPath: "/src/foo/bar/parse.go", StartLine: 12, Complexity: 5, CRAPScore: 30},
+		{Package: "foo/bar", Name: "Get", Complexity: 1, CoveragePercent: 100, CRAPScore: 1},
+	})
+}
+
+func Test_Verifier_MaxCRAP(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	input := map[string][]profile.FunctionCoverage{
+		"foo/bar": []profile.FunctionCoverage{
+			{Name: "Parse", StatementCount: 4, Function: functions.Function{Complexity: 5}},
+			{Name: "Get", CoveredCount: 1, StatementCount: 1, Function: functions.Function{Complexity: 1}},
+		},
+	}
+
+	r, err := (&Verifier{}).Report(input, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(r.Pass).To(BeTrue())
+	g.Expect(r.Packages[0].Functions[0].CRAPScore).To(Equal(30.0))
+	g.Expect(r.Packages[0].Functions[0].Complexity).To(Equal(5))
+
+	r, err = (&Verifier{MaxCRAP: 50}).Report(input, []byte("max_crap_score: 20\n"))
+	g.Expect(err).To(BeNil())
+	g.Expect(r.Pass).To(BeFalse())
+	g.Expect(r.Packages[0].Pass).To(BeTrue())
+	g.Expect(r.Packages[0].Functions[0].Pass).To(BeFalse())
+	g.Expect(r.Packages[0].Functions[0].MaxCRAPScore).To(Equal(20.0))
+	g.Expect(r.Packages[0].Functions[1].Pass).To(BeTrue())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := mock_reporter.NewMocklogger(ctrl)
+	mockLogger.EXPECT().Printf(gomock.Any(), 30.0, "Parse", "foo/bar", 20.0, 5, 0.0).Times(2)
+	mockLogger.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	_, err = (&Verifier{Out: mockLogger, MaxCRAP: 20}).ReportCoverage(input, false, nil)
+	g.Expect(err).To(MatchError("functions exceeded maximum crap score"))
+
+	_, err = (&Verifier{Out: mockLogger, MaxCRAP: 20, MinCov: 90}).ReportCoverage(input, false, nil)
+	g.Expect(err).To(MatchError("packages failed to meet minimum coverage"))
+
+	_, err = (&Verifier{Out: mockLogger, MaxCRAP: 50}).ReportCoverage(input, false, nil)
+	g.Expect(err).To(BeNil())
+}
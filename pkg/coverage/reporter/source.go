@@ -0,0 +1,79 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"golang.org/x/tools/cover"
+)
+
+type coverageState int
+
+const (
+	stateNotApplicable coverageState = iota
+	stateCovered
+	stateUncovered
+)
+
+type srcSegment struct {
+	Text  string
+	State coverageState
+}
+
+// srcSegments splits src[start:end] into runs of source which are covered, uncovered or not applicable to coverage
+// according to the profile boundaries
+func srcSegments(src []byte, boundaries []cover.Boundary, start, end int) []srcSegment {
+	if start < 0 {
+		start = 0
+	}
+
+	if end > len(src) {
+		end = len(src)
+	}
+
+	segments := make([]srcSegment, 0)
+	state := stateNotApplicable
+	segStart := start
+	bi := 0
+
+	for i := start; i < end; i++ {
+		for ; bi < len(boundaries) && boundaries[bi].Offset <= i; bi++ {
+			b := boundaries[bi]
+			if b.Offset != i {
+				continue
+			}
+
+			if segStart < i {
+				segments = append(segments, srcSegment{Text: string(src[segStart:i]), State: state})
+			}
+
+			segStart = i
+
+			switch {
+			case !b.Start:
+				state = stateNotApplicable
+			case b.Norm == 0:
+				state = stateUncovered
+			default:
+				state = stateCovered
+			}
+		}
+	}
+
+	if segStart < end {
+		segments = append(segments, srcSegment{Text: string(src[segStart:end]), State: state})
+	}
+
+	return segments
+}
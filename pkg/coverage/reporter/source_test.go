@@ -0,0 +1,52 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+func Test_srcSegments(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	src := []byte("abcdefghij")
+	boundaries := []cover.Boundary{
+		{Offset: 2, Start: true, Count: 1, Norm: 1},
+		{Offset: 4, Start: false},
+		{Offset: 6, Start: true, Count: 0, Norm: 0},
+		{Offset: 8, Start: false},
+	}
+
+	g.Expect(srcSegments(src, boundaries, 0, len(src))).To(Equal([]srcSegment{
+		{Text: "ab", State: stateNotApplicable},
+		{Text: "cd", State: stateCovered},
+		{Text: "ef", State: stateNotApplicable},
+		{Text: "gh", State: stateUncovered},
+		{Text: "ij", State: stateNotApplicable},
+	}))
+
+	g.Expect(srcSegments(src, boundaries, 3, 7)).To(Equal([]srcSegment{
+		{Text: "d", State: stateNotApplicable},
+		{Text: "ef", State: stateNotApplicable},
+		{Text: "g", State: stateUncovered},
+	}))
+
+	g.Expect(srcSegments(src, nil, -1, 20)).To(Equal([]srcSegment{
+		{Text: "abcdefghij", State: stateNotApplicable},
+	}))
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"github.com/cvgw/gocheckcov/pkg/coverage/suggest"
+)
+
+// Suggest returns the plan for raising the coverage of pkg to the minimum it is held to by the config, or by MinCov
+// without a config. Functions excluded by the config are left out.
+func (v Verifier) Suggest(
+	pkg string,
+	packageToFunctions map[string][]profile.FunctionCoverage,
+	configFile []byte,
+) (suggest.Plan, error) {
+	cfg, err := parseConfig(configFile)
+	if err != nil {
+		return suggest.Plan{}, err
+	}
+
+	if _, ok := packageToFunctions[pkg]; !ok {
+		return suggest.Plan{}, fmt.Errorf("could not find package %v", pkg)
+	}
+
+	packageToFunctions = excludeFunctions(packageToFunctions, cfg)
+	cfgPkg := v.packageConfigs([]string{pkg}, cfg)[0]
+
+	return suggest.NewPlan(pkg, analyzer.NewPackageCoverages(packageToFunctions), cfgPkg.MinCoveragePercentage)
+}
+
+func WriteSuggestJSON(w io.Writer, p suggest.Plan) error {
+	return writeJSON(w, p)
+}
+
+// PrintSuggestions prints the coverage of the package, how many statements must be covered to reach its minimum and
+// up to top functions to cover first. top of zero prints every function.
+func (v Verifier) PrintSuggestions(p suggest.Plan, top int) {
+	v.Out.Printf(
+		"pkg  %v\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v\n",
+		p.Package,
+		p.CoveragePercent,
+		p.MinCoveragePercentage,
+		p.ExecutedCount,
+		p.StatementCount,
+	)
+
+	if p.NeededCount == 0 {
+		v.Out.Printf("package meets its minimum coverage\n")
+	} else {
+		v.Out.Printf("cover %v more statements to reach the minimum\n", p.NeededCount)
+	}
+
+	suggestions := p.Suggestions
+	if top > 0 && len(suggestions) > top {
+		suggestions = suggestions[:top]
+	}
+
+	for _, s := range suggestions {
+		reaches := ""
+		if s.ReachesMinimum {
+			reaches = "reaches minimum"
+		}
+
+		v.Out.Printf(
+			"+%v%% \tfunc %v\t%v\tuncovered statements\t%v\tlines %v\t%v\n",
+			s.GainPercent,
+			s.Function,
+			fileLocation(s.SrcPath, s.StartLine),
+			s.UncoveredCount,
+			formatLines(s.UncoveredLines),
+			reaches,
+		)
+	}
+}
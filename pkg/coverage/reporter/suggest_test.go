@@ -0,0 +1,69 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/mocks/coverage/mock_reporter"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Verifier_Suggest(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	input := map[string][]profile.FunctionCoverage{
+		"foo/bar": []profile.FunctionCoverage{
+			{Name: "Parse", CoveredCount: 1, StatementCount: 5},
+			{Name: "debug", StatementCount: 5},
+		},
+	}
+	configData := []byte(`
+packages:
+- name: foo/bar
+  min_coverage_percentage: 60
+functions:
+- package: foo/bar
+  name: debug
+  exclude: true
+`)
+
+	_, err := (&Verifier{}).Suggest("foo/baz", input, configData)
+	g.Expect(err).ToNot(BeNil())
+
+	p, err := (&Verifier{}).Suggest("foo/bar", input, configData)
+	g.Expect(err).To(BeNil())
+	g.Expect(p.MinCoveragePercentage).To(Equal(float64(60)))
+	g.Expect(p.StatementCount).To(Equal(int64(5)))
+	g.Expect(p.NeededCount).To(Equal(int64(2)))
+	g.Expect(p.Suggestions).To(HaveLen(1))
+
+	p, err = (&Verifier{MinCov: 100}).Suggest("foo/bar", input, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(p.NeededCount).To(Equal(int64(9)))
+	g.Expect(p.Suggestions).To(HaveLen(2))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := mock_reporter.NewMocklogger(ctrl)
+	mockLogger.EXPECT().Printf(gomock.Any(), "foo/bar", float64(10), float64(100), int64(1), int64(10)).Times(1)
+	mockLogger.EXPECT().Printf(gomock.Any(), int64(9)).Times(1)
+	mockLogger.EXPECT().Printf(gomock.Any(), float64(50), "debug", "", int64(5), "", "").Times(1)
+
+	(&Verifier{Out: mockLogger}).PrintSuggestions(p, 1)
+}
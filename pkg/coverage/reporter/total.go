@@ -0,0 +1,90 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/config"
+)
+
+// TotalReport is the coverage of every package together, or of the packages of a module when Module is set
+type TotalReport struct {
+	Module                string  `json:"module,omitempty"`
+	Packages              int     `json:"packages"`
+	ExecutedCount         int64   `json:"executed_count"`
+	StatementCount        int64   `json:"statement_count"`
+	CoveragePercent       float64 `json:"coverage_percentage"`
+	MinCoveragePercentage float64 `json:"min_coverage_percentage,omitempty"`
+	Pass                  bool    `json:"pass"`
+}
+
+func newTotalReport(t analyzer.Total, minCov float64) TotalReport {
+	return TotalReport{
+		Module:                t.Module,
+		Packages:              t.Packages,
+		ExecutedCount:         t.ExecutedCount,
+		StatementCount:        t.StatementCount,
+		CoveragePercent:       t.CoveragePercent,
+		MinCoveragePercentage: minCov,
+		Pass:                  minCov <= t.CoveragePercent,
+	}
+}
+
+func newModuleReports(totals []analyzer.Total) []TotalReport {
+	out := make([]TotalReport, 0, len(totals))
+
+	for _, t := range totals {
+		out = append(out, newTotalReport(t, 0))
+	}
+
+	return out
+}
+
+// printTotals prints the coverage of each module, when there is more than one, and of every package together, if
+// there are any
+func (v Verifier) printTotals(r Report) {
+	if len(r.Modules) > 1 {
+		for _, m := range r.Modules {
+			v.Out.Printf(
+				"module %v\tcoverage %v%% \t\tstatements\t%v/%v\n",
+				m.Module,
+				m.CoveragePercent,
+				m.ExecutedCount,
+				m.StatementCount,
+			)
+		}
+	}
+
+	if r.Total.Packages == 0 {
+		return
+	}
+
+	v.Out.Printf(
+		"total\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v\n",
+		r.Total.CoveragePercent,
+		r.Total.MinCoveragePercentage,
+		r.Total.ExecutedCount,
+		r.Total.StatementCount,
+	)
+}
+
+// totalMinCov returns the minimum total coverage from the config, falling back to the one set on the verifier
+func (v Verifier) totalMinCov(cfg *config.ConfigFile) float64 {
+	if cfg != nil && cfg.TotalMinCoveragePercentage > 0 {
+		return cfg.TotalMinCoveragePercentage
+	}
+
+	return v.TotalMinCov
+}
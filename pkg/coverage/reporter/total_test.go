@@ -0,0 +1,75 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/mocks/coverage/mock_reporter"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Verifier_TotalCoverage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	input := map[string][]profile.FunctionCoverage{
+		"foo/a": []profile.FunctionCoverage{
+			{CoveredCount: 1, StatementCount: 1, Module: "foo"},
+		},
+		"foo/b": []profile.FunctionCoverage{
+			{CoveredCount: 0, StatementCount: 7, Module: "foo"},
+		},
+		"foo/tools/c": []profile.FunctionCoverage{
+			{CoveredCount: 4, StatementCount: 4, Module: "foo/tools"},
+		},
+	}
+
+	r, err := (&Verifier{TotalMinCov: 40}).Report(input, nil)
+	g.Expect(err).To(BeNil())
+	g.Expect(r.Pass).To(BeTrue())
+	g.Expect(r.Total.ExecutedCount).To(Equal(int64(5)))
+	g.Expect(r.Total.StatementCount).To(Equal(int64(12)))
+	g.Expect(r.Total.CoveragePercent).To(Equal(41.66))
+	g.Expect(r.Modules).To(HaveLen(2))
+	g.Expect(r.Modules[0].Module).To(Equal("foo"))
+
+	// the config wins over the flag and fails the report even though every package passes
+	r, err = (&Verifier{TotalMinCov: 40}).Report(input, []byte("total_min_coverage_percentage: 50\n"))
+	g.Expect(err).To(BeNil())
+	g.Expect(r.Pass).To(BeFalse())
+	g.Expect(r.Total.MinCoveragePercentage).To(Equal(float64(50)))
+
+	for _, p := range r.Packages {
+		g.Expect(p.Pass).To(BeTrue())
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := mock_reporter.NewMocklogger(ctrl)
+	mockLogger.EXPECT().Printf(gomock.Any(), "foo", 12.5, int64(1), int64(8)).Times(2)
+	mockLogger.EXPECT().Printf(gomock.Any(), "foo/tools", float64(100), int64(4), int64(4)).Times(2)
+	mockLogger.EXPECT().Printf(gomock.Any(), 41.66, float64(40), int64(5), int64(12)).Times(1)
+	mockLogger.EXPECT().Printf(gomock.Any(), 41.66, float64(50), int64(5), int64(12)).Times(1)
+	mockLogger.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	_, err = (&Verifier{Out: mockLogger, TotalMinCov: 40}).ReportCoverage(input, false, nil)
+	g.Expect(err).To(BeNil())
+
+	_, err = (&Verifier{Out: mockLogger, TotalMinCov: 50}).ReportCoverage(input, false, nil)
+	g.Expect(err).ToNot(BeNil())
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"strings"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/config"
+)
+
+// TreeReport is the coverage of the packages under a directory with a tree rule in the config. Missing is true when
+// there are no packages under the directory.
+type TreeReport struct {
+	Path                  string  `json:"path"`
+	ExecutedCount         int64   `json:"executed_count"`
+	StatementCount        int64   `json:"statement_count"`
+	CoveragePercent       float64 `json:"coverage_percentage"`
+	MinCoveragePercentage float64 `json:"min_coverage_percentage"`
+	Pass                  bool    `json:"pass"`
+	Missing               bool    `json:"missing,omitempty"`
+}
+
+// newTreeReports returns a report for each tree rule in the config. A rule for a directory without any packages
+// fails, since it most likely has a typo.
+func newTreeReports(tree *analyzer.Tree, cfg *config.ConfigFile) []TreeReport {
+	if cfg == nil {
+		return nil
+	}
+
+	out := make([]TreeReport, 0, len(cfg.Trees))
+
+	for _, rule := range cfg.Trees {
+		tr := TreeReport{Path: rule.Path, MinCoveragePercentage: rule.MinCoveragePercentage, Missing: true}
+
+		if node, ok := tree.Find(rule.Path); ok {
+			tr.ExecutedCount = node.ExecutedCount
+			tr.StatementCount = node.StatementCount
+			tr.CoveragePercent = node.CoveragePercent
+			tr.Pass = rule.MinCoveragePercentage <= node.CoveragePercent
+			tr.Missing = false
+		}
+
+		out = append(out, tr)
+	}
+
+	return out
+}
+
+// printTreeReports prints the coverage of each directory with a tree rule
+func (v Verifier) printTreeReports(trees []TreeReport) {
+	for _, tr := range trees {
+		if tr.Missing {
+			v.Out.Printf("tree %v\tno packages\n", tr.Path)
+			continue
+		}
+
+		v.Out.Printf(
+			"tree %v\tcoverage %v%% \tminimum %v%% \tstatements\t%v/%v\n",
+			tr.Path,
+			tr.CoveragePercent,
+			tr.MinCoveragePercentage,
+			tr.ExecutedCount,
+			tr.StatementCount,
+		)
+	}
+}
+
+// treesPass reports whether every directory met its minimum
+func treesPass(trees []TreeReport) bool {
+	for _, tr := range trees {
+		if !tr.Pass {
+			return false
+		}
+	}
+
+	return true
+}
+
+// printTree prints the coverage of every directory below the root, each indented under its parent. Directories
+// which only hold a single directory are printed together with it.
+func (v Verifier) printTree(root *analyzer.Tree) {
+	for _, c := range root.Children {
+		v.printTreeNode(c, "", 0)
+	}
+}
+
+func (v Verifier) printTreeNode(node *analyzer.Tree, parent string, depth int) {
+	for !node.Package && len(node.Children) == 1 {
+		node = node.Children[0]
+	}
+
+	name := strings.TrimPrefix(strings.TrimPrefix(node.Path, parent), "/")
+
+	v.Out.Printf(
+		"%v%v\tcoverage %v%% \tstatements\t%v/%v\n",
+		strings.Repeat("  ", depth),
+		name,
+		node.CoveragePercent,
+		node.ExecutedCount,
+		node.StatementCount,
+	)
+
+	for _, c := range node.Children {
+		v.printTreeNode(c, node.Path, depth+1)
+	}
+}
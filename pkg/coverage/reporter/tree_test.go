@@ -0,0 +1,107 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reporter
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/mocks/coverage/mock_reporter"
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+)
+
+func Test_Verifier_Trees(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	input := map[string][]profile.FunctionCoverage{
+		"foo/internal/billing": []profile.FunctionCoverage{
+			{CoveredCount: 1, StatementCount: 4},
+		},
+		"foo/internal/billing/invoice": []profile.FunctionCoverage{
+			{CoveredCount: 8, StatementCount: 8},
+		},
+		"foo/cmd": []profile.FunctionCoverage{
+			{CoveredCount: 0, StatementCount: 4},
+		},
+	}
+	passConfig := []byte(`
+trees:
+- path: foo/internal/billing/...
+  min_coverage_percentage: 75
+`)
+	failConfig := []byte(`
+trees:
+- path: foo/internal/billing/...
+  min_coverage_percentage: 80
+- path: foo/internal/auth
+  min_coverage_percentage: 10
+`)
+
+	r, err := (&Verifier{}).Report(input, passConfig)
+	g.Expect(err).To(BeNil())
+	g.Expect(r.Pass).To(BeTrue())
+	g.Expect(r.Trees).To(HaveLen(1))
+	g.Expect(r.Trees[0].StatementCount).To(Equal(int64(12)))
+	g.Expect(r.Trees[0].CoveragePercent).To(Equal(float64(75)))
+
+	r, err = (&Verifier{}).Report(input, failConfig)
+	g.Expect(err).To(BeNil())
+	g.Expect(r.Pass).To(BeFalse())
+	g.Expect(r.Trees[0].Pass).To(BeFalse())
+	g.Expect(r.Trees[1].Missing).To(BeTrue())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := mock_reporter.NewMocklogger(ctrl)
+	mockLogger.EXPECT().Printf("tree %v\tno packages\n", "foo/internal/auth").Times(1)
+	mockLogger.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	_, err = (&Verifier{Out: mockLogger}).ReportCoverage(input, false, passConfig)
+	g.Expect(err).To(BeNil())
+
+	_, err = (&Verifier{Out: mockLogger}).ReportCoverage(input, false, failConfig)
+	g.Expect(err).ToNot(BeNil())
+}
+
+func Test_Verifier_printTree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pc := analyzer.NewPackageCoverages(map[string][]profile.FunctionCoverage{
+		"github.com/foo/bar/internal/billing": []profile.FunctionCoverage{
+			{CoveredCount: 1, StatementCount: 4},
+		},
+		"github.com/foo/bar/internal/billing/invoice": []profile.FunctionCoverage{
+			{CoveredCount: 8, StatementCount: 8},
+		},
+		"github.com/foo/bar/cmd": []profile.FunctionCoverage{
+			{CoveredCount: 0, StatementCount: 4},
+		},
+	})
+
+	mockLogger := mock_reporter.NewMocklogger(ctrl)
+
+	gomock.InOrder(
+		mockLogger.EXPECT().Printf(gomock.Any(), "", "github.com/foo/bar", float64(56.25), int64(9), int64(16)),
+		mockLogger.EXPECT().Printf(gomock.Any(), "  ", "cmd", float64(0), int64(0), int64(4)),
+		mockLogger.EXPECT().Printf(gomock.Any(), "  ", "internal/billing", float64(75), int64(9), int64(12)),
+		mockLogger.EXPECT().Printf(gomock.Any(), "    ", "invoice", float64(100), int64(8), int64(8)),
+	)
+
+	(&Verifier{Out: mockLogger}).printTree(analyzer.NewTree(pc))
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package risk
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+)
+
+// Function is the risk of changing a function, combining its cyclomatic complexity with its coverage
+type Function struct {
+	Package         string  `json:"package"`
+	Name            string  `json:"name"`
+	SrcPath         string  `json:"src_path"`
+	StartLine       int     `json:"start_line"`
+	Complexity      int     `json:"complexity"`
+	CoveragePercent float64 `json:"coverage_percentage"`
+	CRAPScore       float64 `json:"crap_score"`
+}
+
+// CRAPScore returns the CRAP score of a function, complexity² × (1 − coverage)³ + complexity, rounded to two decimal
+// places. A fully covered function scores its complexity and an uncovered one its complexity² plus its complexity.
+func CRAPScore(complexity int, coveragePercent float64) float64 {
+	c := float64(complexity)
+	uncovered := 1 - coveragePercent/100
+
+	return math.Round((c*c*math.Pow(uncovered, 3)+c)*100) / 100
+}
+
+// NewFunction returns the risk of the function. Functions without statements count as covered.
+func NewFunction(pkg string, fc profile.FunctionCoverage) Function {
+	cov := analyzer.CoveragePercent(fc.CoveredCount, fc.StatementCount)
+
+	return Function{
+		Package:         pkg,
+		Name:            fc.Name,
+		SrcPath:         fc.Function.SrcPath,
+		StartLine:       fc.Function.StartLine,
+		Complexity:      fc.Function.Complexity,
+		CoveragePercent: cov,
+		CRAPScore:       CRAPScore(fc.Function.Complexity, cov),
+	}
+}
+
+// Rank returns the risk of every function, highest CRAP score first. Functions with the same score are ordered by
+// package and name.
+func Rank(packageToFunctions map[string][]profile.FunctionCoverage) []Function {
+	out := make([]Function, 0)
+
+	for pkg, functions := range packageToFunctions {
+		for _, fc := range functions {
+			out = append(out, NewFunction(pkg, fc))
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CRAPScore != out[j].CRAPScore {
+			return out[i].CRAPScore > out[j].CRAPScore
+		}
+
+		if out[i].Package != out[j].Package {
+			return out[i].Package < out[j].Package
+		}
+
+		return out[i].Name < out[j].Name
+	})
+
+	return out
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package risk
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	. "github.com/onsi/gomega"
+)
+
+func Test_CRAPScore(t *testing.T) {
+	type testcase struct {
+		description     string
+		complexity      int
+		coveragePercent float64
+		expected        float64
+	}
+
+	testCases := []testcase{
+		{
+			description:     "fully covered function scores its complexity",
+			complexity:      5,
+			coveragePercent: 100,
+			expected:        5,
+		},
+		{
+			description:     "uncovered function scores its complexity squared plus its complexity",
+			complexity:      5,
+			coveragePercent: 0,
+			expected:        30,
+		},
+		{
+			description:     "half covered function",
+			complexity:      4,
+			coveragePercent: 50,
+			expected:        6,
+		},
+		{
+			description:     "score is rounded to two decimal places",
+			complexity:      3,
+			coveragePercent: 33.33,
+			expected:        5.67,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(CRAPScore(tc.complexity, tc.coveragePercent)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func Test_Rank(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	input := map[string][]profile.FunctionCoverage{
+		"foo/bar": {
+			{
+				Name:           "Getter",
+				StatementCount: 1,
+				Function:       functions.Function{Complexity: 1, SrcPath: "/src/foo/bar/bar.go", StartLine: 3},
+			},
+			{
+				Name:           "Parse",
+				CoveredCount:   4,
+				StatementCount: 8,
+				Function:       functions.Function{Complexity: 6},
+			},
+		},
+		"baz": {
+			{Name: "Empty", Function: functions.Function{Complexity: 2}},
+			{Name: "Handle", StatementCount: 2, Function: functions.Function{Complexity: 2}},
+		},
+	}
+
+	ranked := Rank(input)
+
+	names := make([]string, 0, len(ranked))
+	for _, f := range ranked {
+		names = append(names, f.Package+"."+f.Name)
+	}
+
+	g.Expect(names).To(Equal([]string{"foo/bar.Parse", "baz.Handle", "baz.Empty", "foo/bar.Getter"}))
+	g.Expect(ranked[0].CRAPScore).To(Equal(10.5))
+	g.Expect(ranked[0].CoveragePercent).To(Equal(float64(50)))
+	g.Expect(ranked[2].CoveragePercent).To(Equal(float64(100)))
+	g.Expect(ranked[3].SrcPath).To(Equal("/src/foo/bar/bar.go"))
+	g.Expect(ranked[3].StartLine).To(Equal(3))
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package suggest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+)
+
+// Plan lists the functions of a package whose uncovered statements would most raise its coverage
+type Plan struct {
+	Package               string  `json:"package"`
+	ExecutedCount         int64   `json:"executed_count"`
+	StatementCount        int64   `json:"statement_count"`
+	CoveragePercent       float64 `json:"coverage_percentage"`
+	MinCoveragePercentage float64 `json:"min_coverage_percentage"`
+	// NeededCount is the number of statements which must be covered for the package to reach its minimum
+	NeededCount int64        `json:"needed_count"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Suggestion is a function with uncovered statements. GainPercent is how much covering all of them would raise the
+// coverage of the package and ReachesMinimum is true for the suggestion which, together with the ones before it,
+// first brings the package to its minimum.
+type Suggestion struct {
+	Function       string  `json:"function"`
+	SrcPath        string  `json:"src_path"`
+	StartLine      int     `json:"start_line"`
+	UncoveredCount int64   `json:"uncovered_count"`
+	UncoveredLines []int   `json:"uncovered_lines"`
+	GainPercent    float64 `json:"gain_percentage"`
+	ReachesMinimum bool    `json:"reaches_minimum"`
+}
+
+// NewPlan returns the functions of the package with uncovered statements, most uncovered statements first, along
+// with the number of statements which must be covered to reach minCov
+func NewPlan(pkg string, pc *analyzer.PackageCoverages, minCov float64) (Plan, error) {
+	cov, ok := pc.Coverage(pkg)
+	if !ok {
+		return Plan{}, fmt.Errorf("could not get coverage for package %v", pkg)
+	}
+
+	p := Plan{
+		Package:               pkg,
+		ExecutedCount:         cov.ExecutedCount,
+		StatementCount:        cov.StatementCount,
+		CoveragePercent:       cov.CoveragePercent,
+		MinCoveragePercentage: minCov,
+		NeededCount:           neededCount(cov.ExecutedCount, cov.StatementCount, minCov),
+		Suggestions:           make([]Suggestion, 0),
+	}
+
+	for _, fc := range cov.Functions {
+		uncovered := fc.StatementCount - fc.CoveredCount
+		if uncovered <= 0 {
+			continue
+		}
+
+		p.Suggestions = append(p.Suggestions, Suggestion{
+			Function:       fc.Name,
+			SrcPath:        fc.Function.SrcPath,
+			StartLine:      fc.Function.StartLine,
+			UncoveredCount: uncovered,
+			UncoveredLines: uncoveredLines(fc),
+			GainPercent:    analyzer.CoveragePercent(uncovered, p.StatementCount),
+		})
+	}
+
+	sort.SliceStable(p.Suggestions, func(i, j int) bool {
+		return p.Suggestions[i].UncoveredCount > p.Suggestions[j].UncoveredCount
+	})
+
+	var covered int64
+
+	for i := range p.Suggestions {
+		if p.NeededCount == 0 {
+			break
+		}
+
+		covered += p.Suggestions[i].UncoveredCount
+		if covered >= p.NeededCount {
+			p.Suggestions[i].ReachesMinimum = true
+			break
+		}
+	}
+
+	return p, nil
+}
+
+// neededCount returns the number of statements which must be covered in addition to executed for the coverage of
+// the package, which is rounded down, to reach minCov. It is at most the number of statements not yet covered.
+func neededCount(executed, statements int64, minCov float64) int64 {
+	if analyzer.CoveragePercent(executed, statements) >= minCov {
+		return 0
+	}
+
+	needed := int64(math.Ceil(minCov*float64(statements)/100)) - executed
+	if needed > statements-executed {
+		// a minimum above 100% can't be reached, covering everything comes closest
+		return statements - executed
+	}
+
+	for executed+needed < statements && analyzer.CoveragePercent(executed+needed, statements) < minCov {
+		needed++
+	}
+
+	return needed
+}
+
+// uncoveredLines returns the lines of the blocks of the function which were not executed. Functions without profile
+// blocks have no uncovered lines, since it is not known which of their statements ran.
+func uncoveredLines(fc profile.FunctionCoverage) []int {
+	seen := make(map[int]bool)
+
+	for _, block := range fc.Blocks {
+		if block.Count > 0 {
+			continue
+		}
+
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			seen[line] = true
+		}
+	}
+
+	lines := make([]int, 0, len(seen))
+	for line := range seen {
+		lines = append(lines, line)
+	}
+
+	sort.Ints(lines)
+
+	return lines
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package suggest
+
+import (
+	"testing"
+
+	"github.com/cvgw/gocheckcov/pkg/coverage/analyzer"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/goparser/functions"
+	"github.com/cvgw/gocheckcov/pkg/coverage/parser/profile"
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/cover"
+)
+
+func Test_NewPlan(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	input := []profile.FunctionCoverage{
+		{Name: "Get", CoveredCount: 1, StatementCount: 1},
+		{
+			Name:           "Parse",
+			CoveredCount:   1,
+			StatementCount: 5,
+			Function:       functions.Function{SrcPath: "/src/foo/parse.go", StartLine: 3},
+			Blocks: []cover.ProfileBlock{
+				{StartLine: 4, EndLine: 4, NumStmt: 1, Count: 1},
+				{StartLine: 5, EndLine: 7, NumStmt: 3, Count: 0},
+				{StartLine: 9, EndLine: 9, NumStmt: 1, Count: 0},
+			},
+		},
+		{Name: "Set", CoveredCount: 0, StatementCount: 2},
+		{Name: "Reset", CoveredCount: 0, StatementCount: 2},
+	}
+
+	p, err := NewPlan("foo", analyzer.NewPackageCoverages(map[string][]profile.FunctionCoverage{"foo": input}), 50)
+	g.Expect(err).To(BeNil())
+	g.Expect(p.ExecutedCount).To(Equal(int64(2)))
+	g.Expect(p.StatementCount).To(Equal(int64(10)))
+	g.Expect(p.CoveragePercent).To(Equal(float64(20)))
+	g.Expect(p.NeededCount).To(Equal(int64(3)))
+
+	names := make([]string, 0, len(p.Suggestions))
+	for _, s := range p.Suggestions {
+		names = append(names, s.Function)
+	}
+
+	g.Expect(names).To(Equal([]string{"Parse", "Set", "Reset"}))
+	g.Expect(p.Suggestions[0].UncoveredCount).To(Equal(int64(4)))
+	g.Expect(p.Suggestions[0].UncoveredLines).To(Equal([]int{5, 6, 7, 9}))
+	g.Expect(p.Suggestions[0].GainPercent).To(Equal(float64(40)))
+	g.Expect(p.Suggestions[0].ReachesMinimum).To(BeTrue())
+	g.Expect(p.Suggestions[1].ReachesMinimum).To(BeFalse())
+	g.Expect(p.Suggestions[1].UncoveredLines).To(BeEmpty())
+
+	_, err = NewPlan("bar", analyzer.NewPackageCoverages(nil), 50)
+	g.Expect(err).ToNot(BeNil())
+}
+
+func Test_neededCount(t *testing.T) {
+	type testcase struct {
+		description string
+		executed    int64
+		statements  int64
+		minCov      float64
+		expected    int64
+	}
+
+	testCases := []testcase{
+		{
+			description: "minimum already met",
+			executed:    5,
+			statements:  10,
+			minCov:      50,
+		},
+		{
+			description: "whole statements",
+			executed:    2,
+			statements:  10,
+			minCov:      50,
+			expected:    3,
+		},
+		{
+			description: "coverage is rounded down",
+			executed:    0,
+			statements:  3,
+			minCov:      66.67,
+			expected:    3,
+		},
+		{
+			description: "minimum of two decimal places",
+			executed:    0,
+			statements:  3,
+			minCov:      66.66,
+			expected:    2,
+		},
+		{
+			description: "minimum above 100",
+			executed:    0,
+			statements:  2,
+			minCov:      101,
+			expected:    2,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(neededCount(tc.executed, tc.statements, tc.minCov)).To(Equal(tc.expected))
+		})
+	}
+}